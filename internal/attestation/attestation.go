@@ -1,9 +1,9 @@
 package attestation
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"autogov-helper/internal/config"
@@ -17,6 +17,64 @@ type MetadataOptions = types.Options
 // options for depscan attestations
 type DepscanOptions = types.DependencyScanOptions
 
+// options for provenance attestations
+type ProvenanceOptions = types.ProvenanceOptions
+
+// options for sbom attestations
+type SBOMOptions = types.SBOMOptions
+
+// options for static analysis attestations
+type StaticAnalysisOptions = types.StaticAnalysisOptions
+
+// options for openvex attestations
+type VEXOptions = types.VEXOptions
+
+// output format for generated attestations
+type OutputFormat string
+
+const (
+	// bare predicate json
+	OutputFormatPredicate OutputFormat = "predicate"
+	// in-toto statement v1 wrapping the predicate
+	OutputFormatStatement OutputFormat = "statement"
+	// dsse envelope holding the signed statement
+	OutputFormatDSSE OutputFormat = "dsse"
+	// sigstore bundle v0.3 holding the dsse envelope
+	OutputFormatBundle OutputFormat = "bundle"
+)
+
+// output settings for generated attestations
+type OutputOptions struct {
+	File   string
+	Format OutputFormat
+	// path to pem private key used for dsse signing
+	SignKey string
+	// optional path to pem certificate for the signing key
+	SignCert string
+}
+
+// reports whether format wraps the predicate in a statement
+func (f OutputFormat) IsStatement() bool {
+	return f != "" && f != OutputFormatPredicate
+}
+
+// reports whether format requires signing
+func (f OutputFormat) IsSigned() bool {
+	return f == OutputFormatDSSE || f == OutputFormatBundle
+}
+
+// parse output format name
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	switch OutputFormat(name) {
+	case "", OutputFormatPredicate:
+		return OutputFormatPredicate, nil
+	case OutputFormatStatement, OutputFormatDSSE, OutputFormatBundle:
+		return OutputFormat(name), nil
+	default:
+		return "", fmt.Errorf("invalid output format %q, must be 'predicate', 'statement', 'dsse' or 'bundle'", name)
+	}
+}
+
 // write output to file or stdout
 func writeOutput(output []byte, outputFile string) error {
 	if outputFile != "" {
@@ -30,7 +88,7 @@ func writeOutput(output []byte, outputFile string) error {
 }
 
 // generate metadata attestation
-func GenerateMetadata(opts types.Options, outputFile string) error {
+func GenerateMetadata(opts types.Options, out OutputOptions) error {
 	m := types.NewFromOptions(opts)
 
 	// validate input
@@ -48,6 +106,26 @@ func GenerateMetadata(opts types.Options, outputFile string) error {
 		return fmt.Errorf("blob requires subjectPath field")
 	}
 
+	// the predicate holds one artifact digest, further subjects need a statement
+	if !out.Format.IsStatement() && opts.Type == types.ArtifactTypeBlob && len(opts.Subjects) > 1 {
+		return fmt.Errorf("predicate output records one blob digest but got %d subjects, "+
+			"use --output-format statement, dsse or bundle", len(opts.Subjects))
+	}
+
+	if out.Format.IsStatement() {
+		output, err := m.GenerateStatement()
+		if err != nil {
+			return errors.WrapError("generate statement", err)
+		}
+
+		// validate against full schema
+		if err := config.ValidateMetadataStatement(output); err != nil {
+			return errors.WrapError("validate metadata statement", err)
+		}
+
+		return writeStatement(output, out)
+	}
+
 	output, err := m.Generate()
 	if err != nil {
 		return errors.WrapError("generate predicate", err)
@@ -58,61 +136,98 @@ func GenerateMetadata(opts types.Options, outputFile string) error {
 		return errors.WrapError("validate metadata", err)
 	}
 
-	return writeOutput(output, outputFile)
+	return writeOutput(output, out.File)
+}
+
+// first non-zero time
+func firstTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
 }
 
 // generate depscan attestation
-func GenerateDepscan(opts types.DependencyScanOptions, outputFile string) error {
+func GenerateDepscan(opts types.DependencyScanOptions, out OutputOptions) error {
 	// read results
 	data, err := os.ReadFile(opts.ResultsPath)
 	if err != nil {
 		return errors.WrapError("read results file", err)
 	}
 
-	// parse results
-	var results types.GrypeResult
-	if err := json.Unmarshal(data, &results); err != nil {
-		return errors.WrapError("parse results", err)
+	format, err := ParseScanFormat(opts.Format)
+	if err != nil {
+		return err
 	}
 
-	// set timestamps
-	opts.StartedAt = time.Now()
-	opts.FinishedAt = time.Now()
-
 	// create scan
 	scan := types.NewDependencyScan(opts)
 
-	// set scanner info
-	scan.Scanner.Name = "grype"
-	scan.Scanner.Version = results.Descriptor.Version
-	scan.Scanner.URI = fmt.Sprintf("https://github.com/anchore/grype/releases/tag/v%s", results.Descriptor.Version)
+	// set scanner info and results from report
+	if err := convertScanResults(format, data, scan); err != nil {
+		return err
+	}
+	if !opts.DBLastUpdate.IsZero() {
+		scan.Scanner.DB.LastUpdate = opts.DBLastUpdate.UTC().Format(time.RFC3339)
+	}
+	if scan.Scanner.DB.LastUpdate == "" {
+		return fmt.Errorf("%s results do not record when the vulnerability database was updated, pass --db-last-update",
+			scan.Scanner.Name)
+	}
 
-	// set db info
-	scan.Scanner.DB.URI = results.Descriptor.Configuration.DB.UpdateURL
-	scan.Scanner.DB.Version = string(results.Descriptor.DB.SchemaVersion)
-	scan.Scanner.DB.LastUpdate = results.Descriptor.DB.Built
+	// set timestamps, explicit times win over those in the report, then the
+	// other explicit time, then now
+	now := time.Now()
+	startedAt := firstTime(opts.StartedAt, scan.ReportStartedAt, opts.FinishedAt, now)
+	finishedAt := firstTime(opts.FinishedAt, scan.ReportFinishedAt, opts.StartedAt, now)
+	if startedAt.After(finishedAt) {
+		return fmt.Errorf("scan start %s is after scan finish %s",
+			startedAt.UTC().Format(time.RFC3339Nano), finishedAt.UTC().Format(time.RFC3339Nano))
+	}
+	scan.SetScanTimes(startedAt, finishedAt)
 
-	// convert results
-	for _, match := range results.Matches {
-		result := types.ScanResult{
-			ID: match.Vulnerability.ID,
-			Severity: []types.Severity{
-				{
-					Method: "nvd",
-					Score:  match.Vulnerability.Severity,
-				},
-			},
+	// annotate findings with vex statements
+	if len(opts.VEXPaths) > 0 {
+		sources, err := loadVEXDocuments(opts.VEXPaths)
+		if err != nil {
+			return err
 		}
+		// without subjects, statements about packages still match
+		subjects, _ := scan.StatementSubjects()
+		applyVEX(sources, subjects, scan, opts.SuppressVEX)
+	}
+
+	// record gate decision, a failed gate is reported after the attestation is written
+	var gateErr error
+	if opts.Gate != nil {
+		gate, err := evaluateGate(*opts.Gate, scan.Scanner.Result)
+		if err != nil {
+			return err
+		}
+		scan.Metadata.Gate = gate
+		if !gate.Passed {
+			gateErr = errors.WithExitCode(ExitCodeGateFailure,
+				fmt.Errorf("vulnerability gate failed: %s", strings.Join(gate.Violations, ", ")))
+		}
+	}
 
-		// add cvss score if available
-		if len(match.Vulnerability.CVSS) > 0 {
-			result.Severity = append(result.Severity, types.Severity{
-				Method: "cvss_score",
-				Score:  fmt.Sprintf("%.1f", match.Vulnerability.CVSS[0].Metrics.BaseScore),
-			})
+	if out.Format.IsStatement() {
+		output, err := scan.GenerateStatement()
+		if err != nil {
+			return errors.WrapError("generate statement", err)
 		}
 
-		scan.Scanner.Result = append(scan.Scanner.Result, result)
+		// validate against full schema
+		if err := config.ValidateDepscanStatement(output); err != nil {
+			return errors.WrapError("validate depscan statement", err)
+		}
+
+		if err := writeStatement(output, out); err != nil {
+			return err
+		}
+		return gateErr
 	}
 
 	// generate output
@@ -126,5 +241,185 @@ func GenerateDepscan(opts types.DependencyScanOptions, outputFile string) error
 		return errors.WrapError("validate depscan", err)
 	}
 
-	return writeOutput(output, outputFile)
+	if err := writeOutput(output, out.File); err != nil {
+		return err
+	}
+	return gateErr
+}
+
+// generate slsa provenance attestation
+func GenerateProvenance(opts types.ProvenanceOptions, out OutputOptions) error {
+	provenance, err := types.NewProvenance(opts)
+	if err != nil {
+		return err
+	}
+
+	if out.Format.IsStatement() {
+		output, err := provenance.GenerateStatement()
+		if err != nil {
+			return errors.WrapError("generate statement", err)
+		}
+
+		// validate against full schema
+		if err := config.ValidateProvenanceStatement(output); err != nil {
+			return errors.WrapError("validate provenance statement", err)
+		}
+
+		return writeStatement(output, out)
+	}
+
+	output, err := provenance.Generate()
+	if err != nil {
+		return errors.WrapError("generate predicate", err)
+	}
+
+	// validate against schema
+	if err := config.ValidateProvenance(output); err != nil {
+		return errors.WrapError("validate provenance", err)
+	}
+
+	return writeOutput(output, out.File)
+}
+
+// generate sbom attestation from spdx or cyclonedx document
+func GenerateSBOM(opts types.SBOMOptions, out OutputOptions) error {
+	data, err := os.ReadFile(opts.DocumentPath)
+	if err != nil {
+		return errors.WrapError("read SBOM file", err)
+	}
+
+	sbom, err := types.ParseSBOM(data)
+	if err != nil {
+		return err
+	}
+	sbom.Subjects = opts.Subjects
+
+	if out.Format.IsStatement() {
+		output, err := sbom.GenerateStatement()
+		if err != nil {
+			return errors.WrapError("generate statement", err)
+		}
+
+		// validate against full schema
+		if err := config.ValidateStatementForPredicateType(output, sbom.PredicateType); err != nil {
+			return errors.WrapError("validate sbom statement", err)
+		}
+
+		return writeStatement(output, out)
+	}
+
+	output, err := sbom.Generate()
+	if err != nil {
+		return errors.WrapError("generate predicate", err)
+	}
+
+	// validate against schema
+	if err := config.ValidateForPredicateType(output, sbom.PredicateType); err != nil {
+		return errors.WrapError("validate sbom", err)
+	}
+
+	return writeOutput(output, out.File)
+}
+
+// generate static analysis attestation from sarif results
+func GenerateStaticAnalysis(opts types.StaticAnalysisOptions, out OutputOptions) error {
+	data, err := os.ReadFile(opts.ResultsPath)
+	if err != nil {
+		return errors.WrapError("read results file", err)
+	}
+
+	report, err := readSARIF(data)
+	if err != nil {
+		return err
+	}
+
+	// invocation times of the log, falling back to now
+	opts.StartedAt = report.StartedAt
+	opts.FinishedAt = report.FinishedAt
+	now := time.Now()
+	if opts.StartedAt.IsZero() {
+		opts.StartedAt = now
+	}
+	if opts.FinishedAt.IsZero() {
+		opts.FinishedAt = now
+	}
+
+	analysis := types.NewStaticAnalysis(opts)
+	analysis.Scanner.Name = report.Name
+	analysis.Scanner.URI = report.URI
+	analysis.Scanner.Version = report.Version
+	analysis.Scanner.Result = report.Findings
+
+	if out.Format.IsStatement() {
+		output, err := analysis.GenerateStatement()
+		if err != nil {
+			return errors.WrapError("generate statement", err)
+		}
+
+		// validate against full schema
+		if err := config.ValidateStaticAnalysisStatement(output); err != nil {
+			return errors.WrapError("validate static analysis statement", err)
+		}
+
+		return writeStatement(output, out)
+	}
+
+	output, err := analysis.Generate()
+	if err != nil {
+		return errors.WrapError("generate predicate", err)
+	}
+
+	// validate against schema
+	if err := config.ValidateStaticAnalysis(output); err != nil {
+		return errors.WrapError("validate static analysis", err)
+	}
+
+	return writeOutput(output, out.File)
+}
+
+// generate openvex attestation from a triage file
+func GenerateVEX(opts types.VEXOptions, out OutputOptions) error {
+	data, err := os.ReadFile(opts.TriagePath)
+	if err != nil {
+		return errors.WrapError("read triage file", err)
+	}
+
+	triage, err := types.ParseVEXTriage(data)
+	if err != nil {
+		return err
+	}
+	if opts.Timestamp.IsZero() {
+		opts.Timestamp = time.Now()
+	}
+
+	doc, err := types.NewVEXDocument(triage, opts)
+	if err != nil {
+		return err
+	}
+
+	if out.Format.IsStatement() {
+		output, err := doc.GenerateStatement()
+		if err != nil {
+			return errors.WrapError("generate statement", err)
+		}
+
+		// validate against full schema
+		if err := config.ValidateStatementForPredicateType(output, types.OpenVEXPredicateTypeURI); err != nil {
+			return errors.WrapError("validate vex statement", err)
+		}
+
+		return writeStatement(output, out)
+	}
+
+	output, err := doc.Generate()
+	if err != nil {
+		return errors.WrapError("generate predicate", err)
+	}
+
+	// validate against schema
+	if err := config.ValidateForPredicateType(output, types.OpenVEXPredicateTypeURI); err != nil {
+		return errors.WrapError("validate vex", err)
+	}
+
+	return writeOutput(output, out.File)
 }
@@ -81,7 +81,7 @@ func TestGenerateMetadata(t *testing.T) {
 		opts.ControlIds = []string{"test-control"}
 
 		// generate metadata
-		err = GenerateMetadata(opts, outputPath)
+		err = GenerateMetadata(opts, OutputOptions{File: outputPath})
 		require.NoError(t, err)
 
 		// verify output file exists
@@ -156,6 +156,75 @@ func TestGenerateMetadata(t *testing.T) {
 		assert.Equal(t, "read", permissions["contents"])
 		assert.Equal(t, "write", permissions["packages"])
 	})
+
+	t.Run("statement_output", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "metadata.json")
+
+		opts := createTestOptions()
+		err := GenerateMetadata(opts, OutputOptions{File: outputPath, Format: OutputFormatStatement})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		var statement map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &statement))
+
+		assert.Equal(t, types.StatementTypeURI, statement["_type"])
+		assert.Equal(t, types.MetadataPredicateTypeURI, statement["predicateType"])
+
+		subjects := statement["subject"].([]interface{})
+		require.Len(t, subjects, 1)
+		subject := subjects[0].(map[string]interface{})
+		assert.Equal(t, "ghcr.io/test-org/test-repo", subject["name"])
+		assert.Equal(t, map[string]interface{}{"sha256": "test"}, subject["digest"])
+
+		predicate := statement["predicate"].(map[string]interface{})
+		artifact := predicate["artifact"].(map[string]interface{})
+		assert.Equal(t, "container-image", artifact["type"])
+	})
+
+	t.Run("statement_output_blob", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "metadata.json")
+
+		opts := createTestOptions()
+		opts.Type = types.ArtifactTypeBlob
+		opts.SubjectPath = "dist/app.tar.gz"
+		opts.Digest = "sha256:abc123"
+		opts.Permissions["packages"] = "none"
+		err := GenerateMetadata(opts, OutputOptions{File: outputPath, Format: OutputFormatStatement})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		var statement types.Statement
+		require.NoError(t, json.Unmarshal(data, &statement))
+		require.Len(t, statement.Subject, 1)
+		assert.Equal(t, "dist/app.tar.gz", statement.Subject[0].Name)
+		assert.Equal(t, "abc123", statement.Subject[0].Digest["sha256"])
+	})
+
+	t.Run("predicate_output_several_blobs", func(t *testing.T) {
+		opts := createTestOptions()
+		opts.Type = types.ArtifactTypeBlob
+		opts.SubjectPath = "dist/app.tar.gz,dist/app.zip"
+		opts.Digest = ""
+		opts.Subjects = []types.Subject{
+			{Name: "dist/app.tar.gz", Digest: map[string]string{"sha256": "abc123"}},
+			{Name: "dist/app.zip", Digest: map[string]string{"sha256": "def456"}},
+		}
+		opts.Permissions["packages"] = "none"
+
+		err := GenerateMetadata(opts, OutputOptions{File: filepath.Join(t.TempDir(), "metadata.json")})
+		assert.ErrorContains(t, err, "--output-format statement")
+
+		err = GenerateMetadata(opts, OutputOptions{
+			File:   filepath.Join(t.TempDir(), "metadata.json"),
+			Format: OutputFormatStatement,
+		})
+		require.NoError(t, err)
+	})
 }
 
 func TestGenerateDepscan(t *testing.T) {
@@ -209,7 +278,7 @@ func TestGenerateDepscan(t *testing.T) {
 		ResultsPath: resultsPath,
 	}
 
-	err = GenerateDepscan(opts, outputPath)
+	err = GenerateDepscan(opts, OutputOptions{File: outputPath})
 	require.NoError(t, err)
 
 	// verify output file exists and contains valid JSON
@@ -219,6 +288,27 @@ func TestGenerateDepscan(t *testing.T) {
 	var result map[string]interface{}
 	err = json.Unmarshal(data, &result)
 	assert.NoError(t, err)
+
+	t.Run("statement_output", func(t *testing.T) {
+		statementPath := filepath.Join(tmpDir, "depscan-statement.json")
+		err := GenerateDepscan(opts, OutputOptions{File: statementPath, Format: OutputFormatStatement})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(statementPath)
+		require.NoError(t, err)
+
+		var statement types.Statement
+		require.NoError(t, json.Unmarshal(data, &statement))
+		assert.Equal(t, types.StatementTypeURI, statement.Type)
+		assert.Equal(t, types.DepscanPredicateTypeURI, statement.PredicateType)
+		require.Len(t, statement.Subject, 1)
+		assert.Equal(t, "test-image", statement.Subject[0].Name)
+		assert.Equal(t, "test", statement.Subject[0].Digest["sha256"])
+
+		var predicate map[string]interface{}
+		require.NoError(t, json.Unmarshal(statement.Predicate, &predicate))
+		assert.NotNil(t, predicate["scanner"])
+	})
 }
 
 func TestGenerateMetadataAttestation(t *testing.T) {
@@ -230,6 +320,168 @@ func TestGenerateMetadataAttestation(t *testing.T) {
 	opts := createTestOptions()
 
 	// Generate metadata
-	err := GenerateMetadata(opts, "")
+	err := GenerateMetadata(opts, OutputOptions{})
 	require.NoError(t, err)
 }
+
+func TestGenerateProvenance(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	opts := ProvenanceOptions{
+		Subjects:          []types.Subject{{Name: "ghcr.io/test-org/test-repo", Digest: map[string]string{"sha256": "abc"}}},
+		ServerURL:         "https://github.com",
+		Repository:        "test-org/test-repo",
+		RepositoryID:      "123",
+		RepositoryOwnerID: "456",
+		WorkflowRef:       "test-org/test-repo/.github/workflows/build.yml@refs/heads/main",
+		Inputs:            map[string]any{"test-input": "test-value"},
+		Event:             "push",
+		Environment:       "github-hosted",
+		Ref:               "refs/heads/main",
+		SHA:               "0123456789abcdef",
+		RunID:             "789",
+		RunAttempt:        "2",
+		StartedAt:         time.Date(2025, 1, 27, 19, 48, 49, 0, time.UTC),
+		FinishedAt:        time.Date(2025, 1, 27, 19, 50, 0, 0, time.UTC),
+	}
+
+	t.Run("predicate_output", func(t *testing.T) {
+		outputPath := filepath.Join(tmpDir, "provenance.json")
+		require.NoError(t, GenerateProvenance(opts, OutputOptions{File: outputPath}))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		var provenance types.Provenance
+		require.NoError(t, json.Unmarshal(data, &provenance))
+		build := provenance.BuildDefinition
+		assert.Equal(t, types.GitHubWorkflowBuildType, build.BuildType)
+		assert.Equal(t, "refs/heads/main", build.ExternalParameters.Workflow.Ref)
+		assert.Equal(t, "https://github.com/test-org/test-repo", build.ExternalParameters.Workflow.Repository)
+		assert.Equal(t, ".github/workflows/build.yml", build.ExternalParameters.Workflow.Path)
+		assert.Equal(t, "test-value", build.ExternalParameters.Inputs["test-input"])
+		assert.Equal(t, "push", build.InternalParameters.GitHub.EventName)
+		require.Len(t, build.ResolvedDependencies, 1)
+		assert.Equal(t, "git+https://github.com/test-org/test-repo@refs/heads/main", build.ResolvedDependencies[0].URI)
+		assert.Equal(t, "0123456789abcdef", build.ResolvedDependencies[0].Digest["gitCommit"])
+
+		run := provenance.RunDetails
+		assert.Equal(t, "https://github.com/test-org/test-repo/.github/workflows/build.yml@refs/heads/main", run.Builder.ID)
+		assert.Equal(t, "https://github.com/test-org/test-repo/actions/runs/789/attempts/2", run.Metadata.InvocationID)
+		assert.Equal(t, "2025-01-27T19:48:49Z", run.Metadata.StartedOn)
+		assert.Equal(t, "2025-01-27T19:50:00Z", run.Metadata.FinishedOn)
+	})
+
+	t.Run("statement_output", func(t *testing.T) {
+		statementPath := filepath.Join(tmpDir, "provenance-statement.json")
+		require.NoError(t, GenerateProvenance(opts, OutputOptions{File: statementPath, Format: OutputFormatStatement}))
+
+		data, err := os.ReadFile(statementPath)
+		require.NoError(t, err)
+
+		var statement types.Statement
+		require.NoError(t, json.Unmarshal(data, &statement))
+		assert.Equal(t, types.ProvenancePredicateTypeURI, statement.PredicateType)
+		require.Len(t, statement.Subject, 1)
+		assert.Equal(t, "abc", statement.Subject[0].Digest["sha256"])
+	})
+
+	t.Run("invalid_workflow_ref", func(t *testing.T) {
+		invalid := opts
+		invalid.WorkflowRef = "test-workflow"
+		err := GenerateProvenance(invalid, OutputOptions{File: filepath.Join(tmpDir, "invalid.json")})
+		assert.ErrorContains(t, err, "invalid workflow ref")
+	})
+}
+
+func TestGenerateSBOM(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	subjects := []types.Subject{{Name: "ghcr.io/test-org/test-repo", Digest: map[string]string{"sha256": "abc"}}}
+	writeSBOM := func(t *testing.T, name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	spdxPath := writeSBOM(t, "sbom.spdx.json", `{
+		"spdxVersion": "SPDX-2.3",
+		"dataLicense": "CC0-1.0",
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"name": "test-repo",
+		"documentNamespace": "https://example.com/spdx/test-repo",
+		"creationInfo": {"created": "2025-01-27T19:48:49Z", "creators": ["Tool: syft-1.19.0"]},
+		"packages": [{"SPDXID": "SPDXRef-Package-a", "name": "a", "versionInfo": "1.0.0"}]
+	}`)
+	cycloneDXPath := writeSBOM(t, "sbom.cdx.json", `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.6",
+		"serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+		"version": 1,
+		"components": [{"type": "library", "name": "a", "version": "1.0.0"}]
+	}`)
+
+	t.Run("spdx_statement", func(t *testing.T) {
+		outputPath := filepath.Join(tmpDir, "spdx-statement.json")
+		opts := SBOMOptions{Subjects: subjects, DocumentPath: spdxPath}
+		require.NoError(t, GenerateSBOM(opts, OutputOptions{File: outputPath, Format: OutputFormatStatement}))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		var statement types.Statement
+		require.NoError(t, json.Unmarshal(data, &statement))
+		assert.Equal(t, types.SPDXPredicateTypeURI, statement.PredicateType)
+
+		var predicate map[string]any
+		require.NoError(t, json.Unmarshal(statement.Predicate, &predicate))
+		assert.Equal(t, "SPDX-2.3", predicate["spdxVersion"])
+		assert.Len(t, predicate["packages"], 1)
+	})
+
+	t.Run("cyclonedx_statement", func(t *testing.T) {
+		outputPath := filepath.Join(tmpDir, "cdx-statement.json")
+		opts := SBOMOptions{Subjects: subjects, DocumentPath: cycloneDXPath}
+		require.NoError(t, GenerateSBOM(opts, OutputOptions{File: outputPath, Format: OutputFormatStatement}))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		var statement types.Statement
+		require.NoError(t, json.Unmarshal(data, &statement))
+		assert.Equal(t, types.CycloneDXPredicateTypeURI, statement.PredicateType)
+		assert.Equal(t, "abc", statement.Subject[0].Digest["sha256"])
+	})
+
+	t.Run("predicate_output", func(t *testing.T) {
+		outputPath := filepath.Join(tmpDir, "cdx-predicate.json")
+		require.NoError(t, GenerateSBOM(SBOMOptions{Subjects: subjects, DocumentPath: cycloneDXPath},
+			OutputOptions{File: outputPath}))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		original, err := os.ReadFile(cycloneDXPath)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(original), string(data))
+	})
+
+	invalid := map[string]string{
+		"unsupported_spdx_version": `{"spdxVersion": "SPDX-2.2", "SPDXID": "SPDXRef-DOCUMENT"}`,
+		"incomplete_spdx":          `{"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT", "name": "x"}`,
+		"old_cyclonedx":            `{"bomFormat": "CycloneDX", "specVersion": "1.4"}`,
+		"unknown_document":         `{"packages": []}`,
+		"malformed_json":           `{"bomFormat": "CycloneDX",`,
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			path := writeSBOM(t, name+".json", content)
+			err := GenerateSBOM(SBOMOptions{Subjects: subjects, DocumentPath: path},
+				OutputOptions{File: filepath.Join(tmpDir, "invalid.json")})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package attestation
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// cvss v3 base metric weights
+var cvssV3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss v2 base metric weights
+var cvssV2Weights = map[string]map[string]float64{
+	"AV": {"L": 0.395, "A": 0.646, "N": 1},
+	"AC": {"H": 0.35, "M": 0.61, "L": 0.71},
+	"Au": {"M": 0.45, "S": 0.56, "N": 0.704},
+	"C":  {"N": 0, "P": 0.275, "C": 0.66},
+	"I":  {"N": 0, "P": 0.275, "C": 0.66},
+	"A":  {"N": 0, "P": 0.275, "C": 0.66},
+}
+
+// base score of a cvss v2 or v3 vector, v4 scores are not calculated
+func cvssBaseScore(vector string) (float64, error) {
+	switch version := cvssVectorVersion(vector); version {
+	case "3.0", "3.1":
+		return cvssV3BaseScore(vector)
+	case "":
+		return cvssV2BaseScore(vector)
+	default:
+		return 0, fmt.Errorf("unsupported CVSS version %s", version)
+	}
+}
+
+// split vector into metric values, the version prefix is dropped
+func cvssMetrics(vector string) map[string]string {
+	metrics := map[string]string{}
+	for _, part := range strings.Split(vector, "/") {
+		if name, value, ok := strings.Cut(part, ":"); ok && name != "CVSS" {
+			metrics[name] = value
+		}
+	}
+	return metrics
+}
+
+// look up the weights of the named metrics
+func cvssWeights(vector string, metrics map[string]string, weights map[string]map[string]float64,
+	names ...string) ([]float64, error) {
+	values := make([]float64, len(names))
+	for i, name := range names {
+		weight, ok := weights[name][metrics[name]]
+		if !ok {
+			return nil, fmt.Errorf("invalid CVSS vector %q: bad %s metric", vector, name)
+		}
+		values[i] = weight
+	}
+	return values, nil
+}
+
+// cvss v3.1 specification base score
+func cvssV3BaseScore(vector string) (float64, error) {
+	metrics := cvssMetrics(vector)
+	w, err := cvssWeights(vector, metrics, cvssV3Weights, "AV", "AC", "UI", "C", "I", "A")
+	if err != nil {
+		return 0, err
+	}
+	changed := metrics["S"] == "C"
+	if !changed && metrics["S"] != "U" {
+		return 0, fmt.Errorf("invalid CVSS vector %q: bad S metric", vector)
+	}
+
+	// privileges required weigh more when the scope changes
+	var pr float64
+	switch metrics["PR"] {
+	case "N":
+		pr = 0.85
+	case "L":
+		pr = 0.62
+		if changed {
+			pr = 0.68
+		}
+	case "H":
+		pr = 0.27
+		if changed {
+			pr = 0.5
+		}
+	default:
+		return 0, fmt.Errorf("invalid CVSS vector %q: bad PR metric", vector)
+	}
+
+	iss := 1 - (1-w[3])*(1-w[4])*(1-w[5])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * w[0] * w[1] * pr * w[2]
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// cvss v3.1 roundup to one decimal, robust to floating point error
+func cvssRoundUp(value float64) float64 {
+	scaled := int(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
+
+// cvss v2 specification base score
+func cvssV2BaseScore(vector string) (float64, error) {
+	w, err := cvssWeights(vector, cvssMetrics(vector), cvssV2Weights, "AV", "AC", "Au", "C", "I", "A")
+	if err != nil {
+		return 0, err
+	}
+
+	impact := 10.41 * (1 - (1-w[3])*(1-w[4])*(1-w[5]))
+	if impact == 0 {
+		return 0, nil
+	}
+	exploitability := 20 * w[0] * w[1] * w[2]
+	return math.Round((0.6*impact+0.4*exploitability-1.5)*1.176*10) / 10, nil
+}
@@ -0,0 +1,34 @@
+package attestation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCVSSBaseScore(t *testing.T) {
+	for _, tc := range []struct {
+		vector string
+		want   float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L", 5.3},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H", 9.9},
+		{"CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 7.5},
+		{"AV:N/AC:M/Au:N/C:N/I:P/A:N", 4.3},
+	} {
+		score, err := cvssBaseScore(tc.vector)
+		require.NoError(t, err, tc.vector)
+		assert.Equal(t, tc.want, score, tc.vector)
+	}
+
+	t.Run("invalid vectors", func(t *testing.T) {
+		_, err := cvssBaseScore("CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L")
+		assert.ErrorContains(t, err, "bad AV metric")
+		_, err = cvssBaseScore("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N")
+		assert.ErrorContains(t, err, "unsupported CVSS version")
+	})
+}
@@ -0,0 +1,176 @@
+package attestation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"autogov-helper/internal/oci"
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// options for fetching stored attestations of a subject
+type FetchOptions struct {
+	// prefixed subject digest looked up in the github attestations api
+	Digest string
+	// owner/repo, defaults to GITHUB_REPOSITORY
+	Repository string
+	// image reference whose oci referrers are fetched instead
+	Image string
+	// predicate type uris or aliases (metadata, depscan, provenance, spdx, cyclonedx), empty keeps all
+	PredicateTypes []string
+}
+
+// attestation found for a subject
+type FetchedAttestation struct {
+	PredicateType string
+	// where the attestation is stored
+	Source string
+	// statement, dsse envelope or sigstore bundle json
+	Content []byte
+}
+
+// predicate type aliases accepted by fetch
+var predicateTypeAliases = map[string]string{
+	"metadata":        types.MetadataPredicateTypeURI,
+	"depscan":         types.DepscanPredicateTypeURI,
+	"provenance":      types.ProvenancePredicateTypeURI,
+	"spdx":            types.SPDXPredicateTypeURI,
+	"cyclonedx":       types.CycloneDXPredicateTypeURI,
+	"static-analysis": types.StaticAnalysisPredicateTypeURI,
+	"openvex":         types.OpenVEXPredicateTypeURI,
+}
+
+// list attestations of a digest from github or of an image from its registry
+func Fetch(ctx context.Context, opts FetchOptions) ([]FetchedAttestation, error) {
+	var fetched []FetchedAttestation
+	var err error
+	switch {
+	case opts.Image != "" && opts.Digest != "":
+		return nil, fmt.Errorf("image and digest cannot be used together")
+	case opts.Image != "":
+		fetched, err = fetchFromRegistry(ctx, opts.Image)
+	case opts.Digest != "":
+		fetched, err = fetchFromGitHub(ctx, opts.Digest, opts.Repository)
+	default:
+		return nil, fmt.Errorf("an image or subject digest is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.PredicateTypes) == 0 {
+		return fetched, nil
+	}
+	wanted := make([]string, len(opts.PredicateTypes))
+	for i, predicateType := range opts.PredicateTypes {
+		wanted[i] = predicateType
+		if uri, ok := predicateTypeAliases[predicateType]; ok {
+			wanted[i] = uri
+		}
+	}
+	return slices.DeleteFunc(fetched, func(a FetchedAttestation) bool {
+		return !slices.Contains(wanted, a.PredicateType)
+	}), nil
+}
+
+// list bundles stored in the github attestations api
+func fetchFromGitHub(ctx context.Context, digest, repository string) ([]FetchedAttestation, error) {
+	alg, value, err := types.ParseDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	owner, repo, err := splitRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newGitHubClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// the endpoint pages with after cursors, which github.ListOptions cannot carry
+	var fetched []FetchedAttestation
+	path := fmt.Sprintf("repos/%s/%s/attestations/%s", owner, repo, types.FormatDigest(alg, value))
+	query := url.Values{"per_page": {"100"}}
+	for {
+		req, err := client.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, errors.WrapError("create list request", err)
+		}
+		var resp github.AttestationsResponse
+		httpResp, err := client.Do(ctx, req, &resp)
+		if err != nil {
+			if httpResp != nil && httpResp.StatusCode == http.StatusNotFound {
+				return nil, nil
+			}
+			return nil, errors.WrapErrorf("list attestations in %s/%s", err, owner, repo)
+		}
+		for _, a := range resp.Attestations {
+			// skip bundles without an in-toto statement
+			_, statement, err := parseAttestation(a.Bundle)
+			if err != nil {
+				continue
+			}
+			fetched = append(fetched, FetchedAttestation{
+				PredicateType: statement.PredicateType,
+				Source:        owner + "/" + repo,
+				Content:       a.Bundle,
+			})
+		}
+		if httpResp.After == "" {
+			return fetched, nil
+		}
+		query.Set("after", httpResp.After)
+	}
+}
+
+// list attestations attached to image in its registry
+func fetchFromRegistry(ctx context.Context, image string) ([]FetchedAttestation, error) {
+	_, artifacts, err := oci.FetchAttestations(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := make([]FetchedAttestation, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		// skip signatures and other non attestation layers
+		_, statement, err := parseAttestation(artifact.Content)
+		if err != nil {
+			continue
+		}
+		fetched = append(fetched, FetchedAttestation{
+			PredicateType: statement.PredicateType,
+			Source:        artifact.Reference,
+			Content:       artifact.Content,
+		})
+	}
+	return fetched, nil
+}
+
+// write attestations to dir named by content digest, returns written paths
+func SaveAttestations(dir string, fetched []FetchedAttestation) ([]string, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, errors.WrapError("create output directory", err)
+	}
+
+	paths := make([]string, 0, len(fetched))
+	for _, a := range fetched {
+		sum := sha256.Sum256(a.Content)
+		path := filepath.Join(dir, "sha256-"+hex.EncodeToString(sum[:])+".json")
+		if err := os.WriteFile(path, a.Content, 0600); err != nil {
+			return nil, errors.WrapError("write attestation", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
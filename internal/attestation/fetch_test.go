@@ -0,0 +1,169 @@
+package attestation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autogov-helper/internal/oci"
+	"autogov-helper/internal/types"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// marshal statement with predicate type for subject digest
+func testTypedStatement(t *testing.T, predicateType, digest string) []byte {
+	t.Helper()
+	var statement types.Statement
+	require.NoError(t, json.Unmarshal(testStatement(t, digest), &statement))
+	statement.PredicateType = predicateType
+	data, err := json.Marshal(statement)
+	require.NoError(t, err)
+	return data
+}
+
+func TestFetchFromGitHub(t *testing.T) {
+	dir := t.TempDir()
+	var bundles []json.RawMessage
+	for _, predicateType := range []string{types.MetadataPredicateTypeURI, types.DepscanPredicateTypeURI} {
+		data, err := os.ReadFile(writeTestBundle(t, t.TempDir(), testTypedStatement(t, predicateType, "sha256:abc")))
+		require.NoError(t, err)
+		bundles = append(bundles, data)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/org/app/attestations/sha256:abc", func(w http.ResponseWriter, r *http.Request) {
+		var resp struct {
+			Attestations []map[string]json.RawMessage `json:"attestations"`
+		}
+		for _, bundle := range bundles {
+			resp.Attestations = append(resp.Attestations, map[string]json.RawMessage{"bundle": bundle})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	// one bundle per page, linked by an after cursor
+	mux.HandleFunc("GET /api/v3/repos/org/app/attestations/sha256:paged", func(w http.ResponseWriter, r *http.Request) {
+		var resp struct {
+			Attestations []map[string]json.RawMessage `json:"attestations"`
+		}
+		switch r.URL.Query().Get("after") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?after=cursor1&per_page=100>; rel="next"`,
+				"http://"+r.Host, r.URL.Path))
+			resp.Attestations = append(resp.Attestations, map[string]json.RawMessage{"bundle": bundles[0]})
+		case "cursor1":
+			resp.Attestations = append(resp.Attestations, map[string]json.RawMessage{"bundle": bundles[1]})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("GH_TOKEN", "test-token")
+	t.Setenv("GITHUB_API_URL", server.URL+"/api/v3")
+	t.Setenv("GITHUB_REPOSITORY", "org/app")
+
+	t.Run("all predicate types", func(t *testing.T) {
+		fetched, err := Fetch(context.Background(), FetchOptions{Digest: "sha256:abc"})
+		require.NoError(t, err)
+		require.Len(t, fetched, 2)
+		assert.Equal(t, "org/app", fetched[0].Source)
+
+		paths, err := SaveAttestations(dir, fetched)
+		require.NoError(t, err)
+		require.Len(t, paths, 2)
+		data, err := os.ReadFile(paths[1])
+		require.NoError(t, err)
+		assert.JSONEq(t, string(bundles[1]), string(data))
+	})
+
+	t.Run("predicate type alias", func(t *testing.T) {
+		fetched, err := Fetch(context.Background(), FetchOptions{Digest: "abc", PredicateTypes: []string{"depscan"}})
+		require.NoError(t, err)
+		require.Len(t, fetched, 1)
+		assert.Equal(t, types.DepscanPredicateTypeURI, fetched[0].PredicateType)
+	})
+
+	t.Run("cursor pages", func(t *testing.T) {
+		fetched, err := Fetch(context.Background(), FetchOptions{Digest: "sha256:paged"})
+		require.NoError(t, err)
+		require.Len(t, fetched, 2)
+		assert.Equal(t, types.MetadataPredicateTypeURI, fetched[0].PredicateType)
+		assert.Equal(t, types.DepscanPredicateTypeURI, fetched[1].PredicateType)
+	})
+
+	t.Run("no attestations", func(t *testing.T) {
+		fetched, err := Fetch(context.Background(), FetchOptions{Digest: "sha256:def"})
+		require.NoError(t, err)
+		assert.Empty(t, fetched)
+	})
+
+	t.Run("missing source", func(t *testing.T) {
+		_, err := Fetch(context.Background(), FetchOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestFetchFromRegistry(t *testing.T) {
+	server := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithReferrersSupport(true),
+	))
+	defer server.Close()
+
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	tag, err := name.NewTag(strings.TrimPrefix(server.URL, "http://") + "/org/app:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, img))
+
+	// one referrer and one attestation tag layer
+	for mode, predicateType := range map[oci.PushMode]string{
+		oci.PushModeReferrers: types.MetadataPredicateTypeURI,
+		oci.PushModeTag:       types.DepscanPredicateTypeURI,
+	} {
+		inputPath := filepath.Join(t.TempDir(), "statement.json")
+		require.NoError(t, os.WriteFile(inputPath, testTypedStatement(t, predicateType, digest.String()), 0600))
+		_, err := Push(context.Background(), PushOptions{InputPath: inputPath, Image: tag.String(), Mode: mode})
+		require.NoError(t, err)
+	}
+
+	// other artifacts attached to the image, larger than any attestation, are skipped
+	subject, err := remote.Head(tag)
+	require.NoError(t, err)
+	sbom, err := random.Image(5<<20, 1)
+	require.NoError(t, err)
+	sbom = mutate.Subject(mutate.ConfigMediaType(sbom, "application/spdx+json"), *subject).(v1.Image)
+	sbomDigest, err := sbom.Digest()
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag.Context().Digest(sbomDigest.String()), sbom))
+
+	fetched, err := Fetch(context.Background(), FetchOptions{Image: tag.String()})
+	require.NoError(t, err)
+	require.Len(t, fetched, 2)
+	assert.ElementsMatch(t,
+		[]string{types.MetadataPredicateTypeURI, types.DepscanPredicateTypeURI},
+		[]string{fetched[0].PredicateType, fetched[1].PredicateType})
+
+	fetched, err = Fetch(context.Background(), FetchOptions{Image: tag.String(), PredicateTypes: []string{"metadata"}})
+	require.NoError(t, err)
+	require.Len(t, fetched, 1)
+	assert.Contains(t, fetched[0].Source, "@sha256:")
+}
@@ -0,0 +1,146 @@
+package attestation
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"autogov-helper/internal/types"
+)
+
+// exit code when scan findings exceed the gate budget
+const ExitCodeGateFailure = 5
+
+// check scan results against gate budget
+func evaluateGate(gate types.ScanGate, results []types.ScanResult) (*types.GateResult, error) {
+	result := &types.GateResult{
+		FailOn:      gate.FailOn,
+		FixableOnly: gate.FixableOnly,
+		Budget:      map[string]int{},
+		Counts:      map[string]int{},
+	}
+
+	failOnRank := -1
+	if gate.FailOn != "" {
+		failOnRank = slices.Index(types.GateSeverities, gate.FailOn)
+		if failOnRank < 0 {
+			return nil, fmt.Errorf("invalid fail-on severity %q, must be one of %v", gate.FailOn, types.GateSeverities)
+		}
+	}
+	for level, limit := range gate.MaxFindings {
+		if !slices.Contains(types.GateSeverities, level) {
+			return nil, fmt.Errorf("invalid budget severity %q, must be one of %v", level, types.GateSeverities)
+		}
+		if limit < 0 {
+			return nil, fmt.Errorf("invalid %s budget %d, must not be negative", level, limit)
+		}
+	}
+
+	// fail-on allows no findings at or above its level, budgets allow up to max
+	for rank, level := range types.GateSeverities {
+		if rank <= failOnRank {
+			result.Budget[level] = 0
+		} else if limit, ok := gate.MaxFindings[level]; ok {
+			result.Budget[level] = limit
+		}
+		result.Counts[level] = 0
+	}
+
+	for _, finding := range results {
+		// vex marks the subject not affected or fixed
+		if finding.VEX.Resolved() {
+			continue
+		}
+		if gate.FixableOnly && !isFixable(finding) {
+			continue
+		}
+		if level := findingSeverity(finding); level != "" {
+			result.Counts[level]++
+		}
+	}
+
+	for _, level := range types.GateSeverities {
+		if budget, ok := result.Budget[level]; ok && result.Counts[level] > budget {
+			result.Violations = append(result.Violations,
+				fmt.Sprintf("%d %s findings exceed budget of %d", result.Counts[level], level, budget))
+		}
+	}
+	result.Passed = len(result.Violations) == 0
+
+	return result, nil
+}
+
+// reports whether a finding has a fixed version available
+func isFixable(finding types.ScanResult) bool {
+	return finding.Fix != nil && finding.Fix.State == "fixed"
+}
+
+// gate severity level of a finding, empty when negligible or unknown
+//
+// prefers a database severity label, then a numeric cvss or security-severity
+// score, then the sarif result level
+func findingSeverity(finding types.ScanResult) string {
+	for _, severity := range finding.Severity {
+		if severity.Method == "sarif_level" {
+			continue
+		}
+		if level, ok := severityLabel(severity.Score); ok {
+			return level
+		}
+	}
+	for _, severity := range finding.Severity {
+		if severity.Method != "security-severity" && !strings.HasPrefix(severity.Method, "cvss") {
+			continue
+		}
+		if score, err := strconv.ParseFloat(severity.Score, 64); err == nil {
+			return cvssSeverity(score)
+		}
+	}
+	for _, severity := range finding.Severity {
+		if severity.Method != "sarif_level" {
+			continue
+		}
+		switch severity.Score {
+		case "error":
+			return "high"
+		case "warning":
+			return "medium"
+		case "note":
+			return "low"
+		}
+	}
+	return ""
+}
+
+// normalize a database severity label, negligible and unknown labels are not levels
+func severityLabel(label string) (string, bool) {
+	switch strings.ToLower(label) {
+	case "critical":
+		return "critical", true
+	case "high", "important":
+		return "high", true
+	case "medium", "moderate":
+		return "medium", true
+	case "low":
+		return "low", true
+	default:
+		return "", false
+	}
+}
+
+// cvss v3 qualitative rating of a base score
+func cvssSeverity(score float64) string {
+	switch {
+	case score >= 9:
+		return "critical"
+	case score >= 7:
+		return "high"
+	case score >= 4:
+		return "medium"
+	case score > 0:
+		return "low"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,118 @@
+package attestation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+	"autogov-helper/internal/util/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateGate(t *testing.T) {
+	fixed := &types.Fix{State: "fixed", Versions: []string{"1.0.1"}}
+	results := []types.ScanResult{
+		{ID: "CVE-1", Severity: []types.Severity{{Method: "nvd", Score: "Critical"}}, Fix: fixed},
+		{ID: "CVE-2", Severity: []types.Severity{{Method: "ghsa", Score: "HIGH"}}, Fix: &types.Fix{State: "not-fixed"}},
+		{ID: "CVE-3", Severity: []types.Severity{{Method: "cvss_v3.1:nvd", Score: "5.3"}}},
+		{ID: "CVE-4", Severity: []types.Severity{{Method: "grype", Score: "Negligible"}}},
+	}
+
+	t.Run("fail on high", func(t *testing.T) {
+		gate, err := evaluateGate(types.ScanGate{FailOn: "high"}, results)
+		require.NoError(t, err)
+		assert.False(t, gate.Passed)
+		assert.Equal(t, map[string]int{"critical": 0, "high": 0}, gate.Budget)
+		assert.Equal(t, map[string]int{"critical": 1, "high": 1, "medium": 1, "low": 0}, gate.Counts)
+		assert.Equal(t, []string{
+			"1 critical findings exceed budget of 0",
+			"1 high findings exceed budget of 0",
+		}, gate.Violations)
+	})
+
+	t.Run("budgets", func(t *testing.T) {
+		gate, err := evaluateGate(types.ScanGate{MaxFindings: map[string]int{"critical": 1, "medium": 0}}, results)
+		require.NoError(t, err)
+		assert.False(t, gate.Passed)
+		assert.Equal(t, []string{"1 medium findings exceed budget of 0"}, gate.Violations)
+	})
+
+	t.Run("fixable only", func(t *testing.T) {
+		gate, err := evaluateGate(types.ScanGate{FailOn: "high", FixableOnly: true}, results[1:])
+		require.NoError(t, err)
+		assert.True(t, gate.Passed)
+		assert.Empty(t, gate.Violations)
+	})
+
+	t.Run("invalid severity", func(t *testing.T) {
+		_, err := evaluateGate(types.ScanGate{FailOn: "severe"}, results)
+		assert.Error(t, err)
+		_, err = evaluateGate(types.ScanGate{MaxFindings: map[string]int{"negligible": 1}}, results)
+		assert.Error(t, err)
+	})
+}
+
+func TestFindingSeverity(t *testing.T) {
+	tests := []struct {
+		name     string
+		severity []types.Severity
+		want     string
+	}{
+		{"label", []types.Severity{{Method: "nvd", Score: "Medium"}, {Method: "cvss_v3.1:nvd", Score: "9.8"}}, "medium"},
+		{"moderate label", []types.Severity{{Method: "ghsa", Score: "MODERATE"}}, "medium"},
+		{"unknown label falls back to cvss", []types.Severity{{Method: "grype", Score: "Unknown"},
+			{Method: "cvss_v3.1:nvd", Score: "9.8"}}, "critical"},
+		{"osv max score", []types.Severity{{Method: "cvss_max", Score: "7.5"}}, "high"},
+		{"sarif security-severity", []types.Severity{{Method: "sarif_level", Score: "note"},
+			{Method: "security-severity", Score: "8.8"}}, "high"},
+		{"sarif level", []types.Severity{{Method: "sarif_level", Score: "warning"}}, "medium"},
+		{"none", []types.Severity{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, findingSeverity(types.ScanResult{Severity: tt.severity}))
+		})
+	}
+}
+
+func TestGenerateDepscanGate(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	resultsPath := filepath.Join(tmpDir, "trivy.json")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(testTrivyReport), 0600))
+	outputPath := filepath.Join(tmpDir, "depscan.json")
+
+	opts := DepscanOptions{
+		Type:         types.ArtifactTypeContainerImage,
+		SubjectName:  "ghcr.io/test-org/test-repo",
+		Digest:       "sha256:test",
+		ResultsPath:  resultsPath,
+		DBLastUpdate: testTrivyDBUpdate,
+		Gate:         &types.ScanGate{FailOn: "high"},
+	}
+	err := GenerateDepscan(opts, OutputOptions{File: outputPath})
+	require.Error(t, err)
+	assert.Equal(t, ExitCodeGateFailure, errors.ExitCode(err))
+	assert.Contains(t, err.Error(), "1 high findings exceed budget of 0")
+
+	// attestation is written with the gate decision
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	var predicate types.DependencyScan
+	require.NoError(t, json.Unmarshal(data, &predicate))
+	require.NotNil(t, predicate.Metadata.Gate)
+	assert.False(t, predicate.Metadata.Gate.Passed)
+	assert.Equal(t, 1, predicate.Metadata.Gate.Counts["high"])
+
+	t.Run("passes fixable only", func(t *testing.T) {
+		opts.Gate = &types.ScanGate{FailOn: "critical", FixableOnly: true}
+		require.NoError(t, GenerateDepscan(opts, OutputOptions{File: outputPath}))
+	})
+}
@@ -26,10 +26,12 @@ type Context struct {
 	EventName   string `json:"event_name"`
 
 	// run info
-	SHA       string `json:"sha"`
-	RunNumber string `json:"run_number"`
-	RunID     string `json:"run_id"`
-	Actor     string `json:"actor"`
+	SHA        string `json:"sha"`
+	Ref        string `json:"ref"`
+	RunNumber  string `json:"run_number"`
+	RunID      string `json:"run_id"`
+	RunAttempt string `json:"run_attempt"`
+	Actor      string `json:"actor"`
 
 	// event info
 	Event struct {
@@ -72,11 +74,13 @@ func LoadGitHubContext() (*Context, error) {
 		RepositoryOwnerID: os.Getenv(env.EnvGitHubOwnerID),
 		ServerURL:         os.Getenv(env.EnvGitHubServerURL),
 		SHA:               os.Getenv(env.EnvGitHubSHA),
+		Ref:               os.Getenv(env.EnvGitHubRef),
 		RefName:           os.Getenv(env.EnvGitHubRefName),
 		EventName:         os.Getenv(env.EnvGitHubEventName),
 		Actor:             os.Getenv(env.EnvGitHubActor),
 		RunID:             os.Getenv(env.EnvGitHubRunID),
 		RunNumber:         os.Getenv(env.EnvGitHubRunNumber),
+		RunAttempt:        os.Getenv(env.EnvGitHubRunAttempt),
 		WorkflowRef:       os.Getenv(env.EnvGitHubWorkflowRef),
 		JobStatus:         os.Getenv("GITHUB_JOB_STATUS"),
 		Inputs:            make(map[string]any),
@@ -0,0 +1,99 @@
+package attestation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"autogov-helper/internal/oci"
+	"autogov-helper/internal/signing"
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// options for pushing attestations to an oci registry
+type PushOptions struct {
+	// statement, dsse envelope or sigstore bundle json
+	InputPath string
+	// image reference (tag or digest) the attestation is attached to
+	Image string
+	Mode  oci.PushMode
+}
+
+// push attestation file as an oci artifact referring to its subject image
+func Push(ctx context.Context, opts PushOptions) (*oci.PushResult, error) {
+	data, err := os.ReadFile(opts.InputPath)
+	if err != nil {
+		return nil, errors.WrapError("read attestation file", err)
+	}
+
+	mediaType, statement, err := parseAttestation(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// only attach attestations that describe the image
+	digest, err := oci.ResolveDigest(ctx, opts.Image)
+	if err != nil {
+		return nil, err
+	}
+	if !hasSubjectDigest(statement.Subject, []string{digest.DigestStr()}) {
+		return nil, fmt.Errorf("no subject in attestation matches image digest %s", digest.DigestStr())
+	}
+
+	return oci.Push(ctx, oci.PushOptions{
+		Subject:       digest.String(),
+		Content:       data,
+		MediaType:     mediaType,
+		PredicateType: statement.PredicateType,
+		Mode:          opts.Mode,
+	})
+}
+
+// detect media type of statement, dsse envelope or bundle and decode its statement
+func parseAttestation(data []byte) (ggcrtypes.MediaType, *types.Statement, error) {
+	var probe struct {
+		Type        string `json:"_type"`
+		MediaType   string `json:"mediaType"`
+		PayloadType string `json:"payloadType"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return "", nil, errors.WrapError("parse attestation", err)
+	}
+
+	var mediaType ggcrtypes.MediaType
+	payload := data
+	switch {
+	case probe.Type == types.StatementTypeURI:
+		mediaType = signing.InTotoPayloadType
+	case probe.MediaType != "" || probe.PayloadType != "":
+		mediaType = signing.EnvelopeMediaType
+		if probe.MediaType != "" {
+			mediaType = ggcrtypes.MediaType(probe.MediaType)
+		}
+		envelope, err := signing.ExtractEnvelope(data)
+		if err != nil {
+			return "", nil, err
+		}
+		if envelope.PayloadType != signing.InTotoPayloadType {
+			return "", nil, fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+		}
+		if payload, err = envelope.DecodePayload(); err != nil {
+			return "", nil, err
+		}
+	default:
+		return "", nil, fmt.Errorf("input is not an in-toto statement, DSSE envelope or Sigstore bundle")
+	}
+
+	var statement types.Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return "", nil, errors.WrapError("parse statement", err)
+	}
+	if statement.Type != types.StatementTypeURI {
+		return "", nil, fmt.Errorf("unsupported statement type %q", statement.Type)
+	}
+	return mediaType, &statement, nil
+}
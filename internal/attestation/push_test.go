@@ -0,0 +1,103 @@
+package attestation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autogov-helper/internal/oci"
+	"autogov-helper/internal/signing"
+	"autogov-helper/internal/types"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// marshal statement with one image subject
+func testStatement(t *testing.T, digest string) []byte {
+	t.Helper()
+	subject, err := types.NewSubject("ghcr.io/org/app", digest)
+	require.NoError(t, err)
+	data, err := json.Marshal(types.Statement{
+		Type:          types.StatementTypeURI,
+		Subject:       []types.Subject{subject},
+		PredicateType: "https://example.com/predicate/v1",
+		Predicate:     json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseAttestation(t *testing.T) {
+	statement := testStatement(t, "sha256:abc")
+
+	t.Run("statement", func(t *testing.T) {
+		mediaType, parsed, err := parseAttestation(statement)
+		require.NoError(t, err)
+		assert.Equal(t, signing.InTotoPayloadType, string(mediaType))
+		assert.Equal(t, "https://example.com/predicate/v1", parsed.PredicateType)
+	})
+
+	t.Run("dsse envelope", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		envelope, err := signing.Sign(statement, signing.InTotoPayloadType, key)
+		require.NoError(t, err)
+		data, err := json.Marshal(envelope)
+		require.NoError(t, err)
+
+		mediaType, parsed, err := parseAttestation(data)
+		require.NoError(t, err)
+		assert.Equal(t, signing.EnvelopeMediaType, string(mediaType))
+		assert.Equal(t, "sha256:abc", parsed.Subject[0].PrimaryDigest())
+	})
+
+	t.Run("predicate only", func(t *testing.T) {
+		_, _, err := parseAttestation([]byte(`{"type":"https://in-toto.io/attestation/metadata"}`))
+		assert.Error(t, err)
+	})
+}
+
+func TestPush(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	tag, err := name.NewTag(strings.TrimPrefix(server.URL, "http://") + "/org/app:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, img))
+
+	t.Run("matching subject", func(t *testing.T) {
+		inputPath := filepath.Join(t.TempDir(), "statement.json")
+		require.NoError(t, os.WriteFile(inputPath, testStatement(t, digest.String()), 0600))
+
+		result, err := Push(context.Background(), PushOptions{InputPath: inputPath, Image: tag.String()})
+		require.NoError(t, err)
+		assert.Equal(t, oci.PushModeTag, result.Mode)
+		assert.Equal(t, digest.String(), result.SubjectDigest)
+	})
+
+	t.Run("subject mismatch", func(t *testing.T) {
+		inputPath := filepath.Join(t.TempDir(), "statement.json")
+		require.NoError(t, os.WriteFile(inputPath, testStatement(t, "sha256:abc"), 0600))
+
+		_, err := Push(context.Background(), PushOptions{InputPath: inputPath, Image: tag.String()})
+		assert.ErrorContains(t, err, "no subject in attestation matches")
+	})
+}
@@ -0,0 +1,194 @@
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+)
+
+// scanner info and findings read from a sarif log
+type sarifReport struct {
+	Name     string
+	URI      string
+	Version  string
+	Findings []types.Finding
+	// earliest invocation start and latest end, zero when not recorded
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// read sarif 2.1.0 log, every run must come from the same tool
+func readSARIF(data []byte) (*sarifReport, error) {
+	var sarifLog types.SARIFLog
+	if err := json.Unmarshal(data, &sarifLog); err != nil {
+		return nil, errors.WrapError("parse SARIF results", err)
+	}
+	if sarifLog.Version != "" && sarifLog.Version != "2.1.0" {
+		return nil, fmt.Errorf("unsupported SARIF version %q, expected 2.1.0", sarifLog.Version)
+	}
+	if len(sarifLog.Runs) == 0 {
+		return nil, fmt.Errorf("SARIF results contain no runs")
+	}
+
+	driver := sarifLog.Runs[0].Tool.Driver
+	report := &sarifReport{
+		Name:     driver.Name,
+		URI:      driver.InformationURI,
+		Version:  driver.Version,
+		Findings: make([]types.Finding, 0),
+	}
+	if report.Version == "" {
+		report.Version = driver.SemanticVersion
+	}
+
+	for _, run := range sarifLog.Runs {
+		// one predicate describes one scanner
+		if run.Tool.Driver.Name != driver.Name || run.Tool.Driver.Version != driver.Version {
+			return nil, fmt.Errorf("SARIF runs come from different tools (%s %s and %s %s), attest each separately",
+				driver.Name, driver.Version, run.Tool.Driver.Name, run.Tool.Driver.Version)
+		}
+		for _, invocation := range run.Invocations {
+			if started, err := time.Parse(time.RFC3339, invocation.StartTimeUTC); err == nil &&
+				(report.StartedAt.IsZero() || started.Before(report.StartedAt)) {
+				report.StartedAt = started
+			}
+			if finished, err := time.Parse(time.RFC3339, invocation.EndTimeUTC); err == nil &&
+				finished.After(report.FinishedAt) {
+				report.FinishedAt = finished
+			}
+		}
+
+		for _, result := range run.Results {
+			finding, err := sarifFinding(run, result)
+			if err != nil {
+				return nil, err
+			}
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+
+	return report, nil
+}
+
+// convert sarif result with the metadata of its rule
+func sarifFinding(run types.SARIFRun, result types.SARIFResult) (types.Finding, error) {
+	rule := sarifRule(run.Tool.Driver.Rules, result)
+
+	finding := types.Finding{
+		ScanResult: types.ScanResult{ID: result.RuleID, Severity: []types.Severity{}},
+		Message:    result.Message.Text,
+	}
+	if finding.ID == "" && rule != nil {
+		finding.ID = rule.ID
+	}
+	if finding.ID == "" {
+		return types.Finding{}, fmt.Errorf("SARIF result without a rule id")
+	}
+
+	// result level overrides the rule default, sarif defaults to warning
+	level := result.Level
+	if level == "" && rule != nil {
+		level = rule.DefaultConfiguration.Level
+	}
+	if level == "" {
+		level = "warning"
+	}
+	finding.Severity = append(finding.Severity, types.Severity{Method: "sarif_level", Score: level})
+
+	// security-severity and tags of the result override those of the rule
+	securitySeverity := result.Properties.SecuritySeverity
+	tags := result.Properties.Tags
+	if rule != nil {
+		if len(securitySeverity) == 0 {
+			securitySeverity = rule.Properties.SecuritySeverity
+		}
+		tags = append(slices.Clone(rule.Properties.Tags), tags...)
+	}
+	if score, ok := sarifSecuritySeverity(securitySeverity); ok {
+		finding.Severity = append(finding.Severity, types.Severity{Method: "security-severity", Score: score})
+	}
+	if len(tags) > 0 {
+		slices.Sort(tags)
+		finding.Tags = slices.Compact(tags)
+	}
+
+	for _, location := range result.Locations {
+		physical := location.PhysicalLocation
+		if physical.ArtifactLocation.URI == "" {
+			continue
+		}
+		finding.Locations = append(finding.Locations, types.Location{
+			URI:       physical.ArtifactLocation.URI,
+			StartLine: physical.Region.StartLine,
+		})
+	}
+
+	return finding, nil
+}
+
+// rule of a result by index, or by id when the index is not set
+func sarifRule(rules []types.SARIFRule, result types.SARIFResult) *types.SARIFRule {
+	if result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(rules) {
+		return &rules[*result.RuleIndex]
+	}
+	for i := range rules {
+		if rules[i].ID == result.RuleID {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// security-severity score, tools write it as a string or a number
+func sarifSecuritySeverity(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 {
+		return "", false
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if text == "" {
+			return "", false
+		}
+		return text, true
+	}
+	var score float64
+	if err := json.Unmarshal(raw, &score); err == nil {
+		return strconv.FormatFloat(score, 'f', 1, 64), true
+	}
+	return "", false
+}
+
+// map sarif log for depscan
+func convertSARIF(data []byte, scan *types.DependencyScan) error {
+	report, err := readSARIF(data)
+	if err != nil {
+		return err
+	}
+
+	scan.Scanner.Name = report.Name
+	scan.Scanner.URI = report.URI
+	scan.Scanner.Version = report.Version
+
+	// sarif does not describe the vulnerability db, see DependencyScanOptions.DBLastUpdate
+
+	// a run missing one invocation time is taken to start and finish together
+	scan.ReportStartedAt = report.StartedAt
+	scan.ReportFinishedAt = report.FinishedAt
+	if scan.ReportStartedAt.IsZero() {
+		scan.ReportStartedAt = report.FinishedAt
+	}
+	if scan.ReportFinishedAt.IsZero() {
+		scan.ReportFinishedAt = report.StartedAt
+	}
+
+	for _, finding := range report.Findings {
+		scan.Scanner.Result = append(scan.Scanner.Result, finding.ScanResult)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package attestation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSARIFReport = `{
+	"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+	"version": "2.1.0",
+	"runs": [
+		{
+			"tool": {
+				"driver": {
+					"name": "CodeQL",
+					"semanticVersion": "2.20.1",
+					"informationUri": "https://codeql.github.com",
+					"rules": [
+						{
+							"id": "go/sql-injection",
+							"defaultConfiguration": {"level": "error"},
+							"properties": {"security-severity": "8.8", "tags": ["security", "external/cwe/cwe-089"]}
+						},
+						{
+							"id": "go/unused-variable",
+							"properties": {"tags": ["maintainability"]}
+						}
+					]
+				}
+			},
+			"invocations": [
+				{"startTimeUtc": "2025-01-24T10:00:00.123Z", "endTimeUtc": "2025-01-24T10:05:00Z", "executionSuccessful": true}
+			],
+			"results": [
+				{
+					"ruleId": "go/sql-injection",
+					"ruleIndex": 0,
+					"message": {"text": "This query depends on a user-provided value."},
+					"locations": [
+						{"physicalLocation": {"artifactLocation": {"uri": "internal/db/query.go"}, "region": {"startLine": 42}}}
+					]
+				},
+				{
+					"ruleId": "go/unused-variable",
+					"level": "note",
+					"message": {"text": "Variable x is not used."},
+					"properties": {"security-severity": 2, "tags": ["security"]}
+				}
+			]
+		}
+	]
+}`
+
+func TestReadSARIF(t *testing.T) {
+	t.Run("driver and rule metadata", func(t *testing.T) {
+		report, err := readSARIF([]byte(testSARIFReport))
+		require.NoError(t, err)
+
+		assert.Equal(t, "CodeQL", report.Name)
+		assert.Equal(t, "2.20.1", report.Version)
+		assert.Equal(t, "https://codeql.github.com", report.URI)
+		assert.Equal(t, "2025-01-24T10:00:00.123Z", report.StartedAt.Format("2006-01-02T15:04:05.000Z07:00"))
+		require.Len(t, report.Findings, 2)
+
+		injection := report.Findings[0]
+		assert.Equal(t, "go/sql-injection", injection.ID)
+		assert.Equal(t, []types.Severity{
+			{Method: "sarif_level", Score: "error"},
+			{Method: "security-severity", Score: "8.8"},
+		}, injection.Severity)
+		assert.Equal(t, []string{"external/cwe/cwe-089", "security"}, injection.Tags)
+		assert.Equal(t, []types.Location{{URI: "internal/db/query.go", StartLine: 42}}, injection.Locations)
+
+		// result level, properties and tags override those of the rule
+		unused := report.Findings[1]
+		assert.Equal(t, []types.Severity{
+			{Method: "sarif_level", Score: "note"},
+			{Method: "security-severity", Score: "2.0"},
+		}, unused.Severity)
+		assert.Equal(t, []string{"maintainability", "security"}, unused.Tags)
+	})
+
+	t.Run("no runs", func(t *testing.T) {
+		_, err := readSARIF([]byte(`{"version": "2.1.0", "runs": []}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("runs of several tools", func(t *testing.T) {
+		_, err := readSARIF([]byte(`{"version": "2.1.0", "runs": [
+			{"tool": {"driver": {"name": "CodeQL", "version": "2.20.1"}}},
+			{"tool": {"driver": {"name": "semgrep", "version": "1.101.0"}}}
+		]}`))
+		assert.ErrorContains(t, err, "different tools")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := readSARIF([]byte(`{"version": "1.0.0", "runs": [{}]}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("depscan results", func(t *testing.T) {
+		scan := types.NewDependencyScan(types.DependencyScanOptions{})
+		require.NoError(t, convertScanResults(ScanFormatAuto, []byte(testSARIFReport), scan))
+
+		assert.Equal(t, "CodeQL", scan.Scanner.Name)
+		assert.Empty(t, scan.Scanner.DB.LastUpdate)
+		assert.Equal(t, "2025-01-24T10:00:00.123Z", scan.ReportStartedAt.Format(time.RFC3339Nano))
+		assert.Equal(t, "2025-01-24T10:05:00Z", scan.ReportFinishedAt.Format(time.RFC3339Nano))
+		require.Len(t, scan.Scanner.Result, 2)
+		assert.Equal(t, "go/sql-injection", scan.Scanner.Result[0].ID)
+	})
+}
+
+func TestGenerateStaticAnalysis(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	resultsPath := filepath.Join(tmpDir, "results.sarif")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(testSARIFReport), 0600))
+	outputPath := filepath.Join(tmpDir, "static-analysis.json")
+
+	opts := StaticAnalysisOptions{
+		Subjects:    []types.Subject{{Name: "ghcr.io/test-org/test-repo", Digest: map[string]string{"sha256": "abc"}}},
+		ResultsPath: resultsPath,
+	}
+	require.NoError(t, GenerateStaticAnalysis(opts, OutputOptions{File: outputPath, Format: OutputFormatStatement}))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	var statement types.Statement
+	require.NoError(t, json.Unmarshal(data, &statement))
+	assert.Equal(t, types.StaticAnalysisPredicateTypeURI, statement.PredicateType)
+
+	var predicate types.StaticAnalysis
+	require.NoError(t, json.Unmarshal(statement.Predicate, &predicate))
+	assert.Equal(t, "CodeQL", predicate.Scanner.Name)
+	assert.Equal(t, "2025-01-24T10:05:00Z", predicate.Metadata.ScanFinishedOn)
+	require.Len(t, predicate.Scanner.Result, 2)
+	assert.Equal(t, "This query depends on a user-provided value.", predicate.Scanner.Result[0].Message)
+}
@@ -0,0 +1,471 @@
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+)
+
+// scanner report format accepted by depscan
+type ScanFormat string
+
+const (
+	// detect format from report content
+	ScanFormatAuto  ScanFormat = "auto"
+	ScanFormatGrype ScanFormat = "grype"
+	ScanFormatTrivy ScanFormat = "trivy"
+	ScanFormatOSV   ScanFormat = "osv"
+	ScanFormatSARIF ScanFormat = "sarif"
+)
+
+// trivy vulnerability db repository, reports do not record it
+const trivyDBRepository = "ghcr.io/aquasecurity/trivy-db"
+
+// parse scanner report format name
+func ParseScanFormat(name string) (ScanFormat, error) {
+	switch ScanFormat(name) {
+	case "", ScanFormatAuto:
+		return ScanFormatAuto, nil
+	case ScanFormatGrype, ScanFormatTrivy, ScanFormatOSV, ScanFormatSARIF:
+		return ScanFormat(name), nil
+	default:
+		return "", fmt.Errorf("invalid results format %q, must be 'auto', 'grype', 'trivy', 'osv' or 'sarif'", name)
+	}
+}
+
+// detect scanner report format from its top level keys
+func detectScanFormat(data []byte) (ScanFormat, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return "", errors.WrapError("parse results", err)
+	}
+
+	has := func(names ...string) bool {
+		return slices.ContainsFunc(names, func(name string) bool {
+			_, ok := keys[name]
+			return ok
+		})
+	}
+	switch {
+	case has("matches", "descriptor"):
+		return ScanFormatGrype, nil
+	case has("SchemaVersion", "Results", "ArtifactName"):
+		return ScanFormatTrivy, nil
+	case has("runs", "$schema"):
+		return ScanFormatSARIF, nil
+	case has("results", "vulns"):
+		return ScanFormatOSV, nil
+	default:
+		return "", fmt.Errorf("unrecognized scan results, expected a Grype, Trivy, OSV-Scanner or SARIF report")
+	}
+}
+
+// fill scanner fields of scan from report
+func convertScanResults(format ScanFormat, data []byte, scan *types.DependencyScan) error {
+	if format == "" || format == ScanFormatAuto {
+		detected, err := detectScanFormat(data)
+		if err != nil {
+			return err
+		}
+		format = detected
+	}
+
+	switch format {
+	case ScanFormatGrype:
+		return convertGrype(data, scan)
+	case ScanFormatTrivy:
+		return convertTrivy(data, scan)
+	case ScanFormatOSV:
+		return convertOSV(data, scan)
+	case ScanFormatSARIF:
+		return convertSARIF(data, scan)
+	default:
+		return fmt.Errorf("unsupported results format %q", format)
+	}
+}
+
+// map grype json report
+func convertGrype(data []byte, scan *types.DependencyScan) error {
+	var results types.GrypeResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return errors.WrapError("parse results", err)
+	}
+
+	// set scanner info
+	scan.Scanner.Name = "grype"
+	scan.Scanner.Version = results.Descriptor.Version
+	scan.Scanner.URI = fmt.Sprintf("https://github.com/anchore/grype/releases/tag/v%s", results.Descriptor.Version)
+
+	// set db info
+	scan.Scanner.DB.URI = results.Descriptor.Configuration.DB.UpdateURL
+	scan.Scanner.DB.Version = string(results.Descriptor.DB.SchemaVersion)
+	scan.Scanner.DB.LastUpdate = results.Descriptor.DB.Built
+
+	// report is written when the scan finishes, its start is not recorded
+	if timestamp, err := time.Parse(time.RFC3339, results.Descriptor.Timestamp); err == nil {
+		scan.ReportStartedAt = timestamp
+		scan.ReportFinishedAt = timestamp
+	}
+
+	// convert results
+	for _, match := range results.Matches {
+		vuln := match.Vulnerability
+		result := types.ScanResult{
+			ID:         vuln.ID,
+			Severity:   grypeSeverities(vuln.GrypeVulnerability),
+			DataSource: vuln.DataSource,
+			Namespace:  vuln.Namespace,
+		}
+
+		// related entries describe the same vulnerability in other dbs
+		for _, related := range match.RelatedVulnerabilities {
+			if related.ID != vuln.ID && !slices.Contains(result.Aliases, related.ID) {
+				result.Aliases = append(result.Aliases, related.ID)
+			}
+			for _, severity := range grypeSeverities(related) {
+				if !slices.Contains(result.Severity, severity) {
+					result.Severity = append(result.Severity, severity)
+				}
+			}
+		}
+
+		if match.Artifact.Name != "" {
+			result.Package = &types.Package{
+				Name:    match.Artifact.Name,
+				Version: match.Artifact.Version,
+				Type:    match.Artifact.Type,
+				PURL:    match.Artifact.PURL,
+			}
+		}
+		if vuln.Fix.State != "" {
+			result.Fix = &types.Fix{State: vuln.Fix.State, Versions: vuln.Fix.Versions}
+		}
+
+		scan.Scanner.Result = append(scan.Scanner.Result, result)
+	}
+
+	return nil
+}
+
+// severity label and every cvss score of a grype vulnerability, labeled by its db
+func grypeSeverities(vuln types.GrypeVulnerability) []types.Severity {
+	db := grypeDatabase(vuln.Namespace)
+	severities := []types.Severity{}
+	if vuln.Severity != "" {
+		severities = append(severities, types.Severity{Method: db, Score: vuln.Severity})
+	}
+	for _, cvss := range vuln.CVSS {
+		version := cvss.Version
+		if version == "" {
+			version = cvssVectorVersion(cvss.Vector)
+		}
+		method := "cvss:" + db
+		if version != "" {
+			method = fmt.Sprintf("cvss_v%s:%s", version, db)
+		}
+		severities = append(severities, types.Severity{
+			Method: method,
+			Score:  fmt.Sprintf("%.1f", cvss.Metrics.BaseScore),
+			Vector: cvss.Vector,
+		})
+	}
+	return severities
+}
+
+// source db of a grype namespace (github:language:go -> ghsa), grype when unknown
+func grypeDatabase(namespace string) string {
+	source, _, _ := strings.Cut(namespace, ":")
+	switch source {
+	case "":
+		return "grype"
+	case "github":
+		return "ghsa"
+	default:
+		return source
+	}
+}
+
+// cvss version of a vector (CVSS:3.1/AV:N/... -> 3.1), empty for unprefixed v2 vectors
+func cvssVectorVersion(vector string) string {
+	prefix, _, found := strings.Cut(vector, "/")
+	if !found {
+		return ""
+	}
+	version, ok := strings.CutPrefix(prefix, "CVSS:")
+	if !ok {
+		return ""
+	}
+	return version
+}
+
+// map trivy json report
+func convertTrivy(data []byte, scan *types.DependencyScan) error {
+	var report types.TrivyResult
+	if err := json.Unmarshal(data, &report); err != nil {
+		return errors.WrapError("parse results", err)
+	}
+
+	// set scanner info
+	scan.Scanner.Name = "trivy"
+	scan.Scanner.Version = report.Trivy.Version
+	scan.Scanner.URI = "https://github.com/aquasecurity/trivy"
+	if report.Trivy.Version != "" {
+		scan.Scanner.URI = fmt.Sprintf("https://github.com/aquasecurity/trivy/releases/tag/v%s", report.Trivy.Version)
+	}
+
+	// report is created when the scan finishes, its start is not recorded
+	scan.ReportStartedAt = report.CreatedAt
+	scan.ReportFinishedAt = report.CreatedAt
+
+	// reports do not record when the db was built, see DependencyScanOptions.DBLastUpdate
+	scan.Scanner.DB.URI = trivyDBRepository
+
+	// convert results
+	for _, target := range report.Results {
+		for _, vuln := range target.Vulnerabilities {
+			source := vuln.SeveritySource
+			if source == "" {
+				source = "trivy"
+			}
+			result := types.ScanResult{
+				ID:       vuln.VulnerabilityID,
+				Severity: []types.Severity{{Method: source, Score: vuln.Severity}},
+			}
+			result.Severity = append(result.Severity, trivyCVSSSeverities(vuln.CVSS)...)
+			if vuln.PkgName != "" {
+				result.Package = &types.Package{
+					Name:    vuln.PkgName,
+					Version: vuln.InstalledVersion,
+					Type:    target.Type,
+					PURL:    vuln.PkgIdentifier.PURL,
+				}
+			}
+			result.Fix = trivyFix(vuln)
+			if vuln.DataSource != nil {
+				result.DataSource = vuln.DataSource.URL
+			}
+
+			scan.Scanner.Result = append(scan.Scanner.Result, result)
+		}
+	}
+
+	return nil
+}
+
+// fix of a trivy vulnerability in grype fix states, nil when unknown
+func trivyFix(vuln types.TrivyVulnerability) *types.Fix {
+	var versions []string
+	if vuln.FixedVersion != "" {
+		versions = strings.Split(vuln.FixedVersion, ", ")
+	}
+
+	switch vuln.Status {
+	case "fixed":
+		return &types.Fix{State: "fixed", Versions: versions}
+	case "affected", "under_investigation", "fix_deferred", "end_of_life":
+		return &types.Fix{State: "not-fixed"}
+	case "will_not_fix":
+		return &types.Fix{State: "wont-fix"}
+	case "":
+		// older reports only record the fixed version
+		if len(versions) > 0 {
+			return &types.Fix{State: "fixed", Versions: versions}
+		}
+	}
+	return nil
+}
+
+// cvss base scores and vectors of every vendor, newest cvss version first
+func trivyCVSSSeverities(cvss map[string]types.TrivyCVSS) []types.Severity {
+	vendors := make([]string, 0, len(cvss))
+	for vendor := range cvss {
+		vendors = append(vendors, vendor)
+	}
+	slices.Sort(vendors)
+
+	var severities []types.Severity
+	for _, vendor := range vendors {
+		scores := cvss[vendor]
+		for _, score := range []struct {
+			version string
+			value   float64
+			vector  string
+		}{
+			{"v4.0", scores.V40Score, scores.V40Vector},
+			{"v3", scores.V3Score, scores.V3Vector},
+			{"v2", scores.V2Score, scores.V2Vector},
+		} {
+			if score.value > 0 {
+				severities = append(severities, types.Severity{
+					Method: fmt.Sprintf("cvss_%s:%s", score.version, vendor),
+					Score:  fmt.Sprintf("%.1f", score.value),
+					Vector: score.vector,
+				})
+			}
+		}
+	}
+	return severities
+}
+
+// map osv-scanner json output or osv.dev vulns
+func convertOSV(data []byte, scan *types.DependencyScan) error {
+	var report types.OSVResult
+	if err := json.Unmarshal(data, &report); err != nil {
+		return errors.WrapError("parse results", err)
+	}
+
+	// osv-scanner does not record its version
+	scan.Scanner.Name = "osv-scanner"
+	scan.Scanner.URI = "https://github.com/google/osv-scanner"
+	scan.Scanner.DB.URI = "https://osv.dev"
+
+	// db is at least as recent as its newest advisory
+	var lastModified time.Time
+	add := func(vuln types.OSVVulnerability, maxSeverity string, pkg *types.Package) {
+		if vuln.Modified.After(lastModified) {
+			lastModified = vuln.Modified
+		}
+		result := osvScanResult(vuln, maxSeverity)
+		result.Package = pkg
+		scan.Scanner.Result = append(scan.Scanner.Result, result)
+	}
+
+	for _, source := range report.Results {
+		for _, pkg := range source.Packages {
+			var scanned *types.Package
+			if pkg.Package.Name != "" {
+				scanned = &types.Package{
+					Name:    pkg.Package.Name,
+					Version: pkg.Package.Version,
+					Type:    pkg.Package.Ecosystem,
+					PURL:    osvPURL(pkg.Package),
+				}
+			}
+			for _, vuln := range pkg.Vulnerabilities {
+				var maxSeverity string
+				for _, group := range pkg.Groups {
+					if slices.Contains(group.IDs, vuln.ID) {
+						maxSeverity = group.MaxSeverity
+					}
+				}
+				add(vuln, maxSeverity, scanned)
+			}
+		}
+	}
+	for _, vuln := range report.Vulns {
+		add(vuln, "", nil)
+	}
+
+	// without advisories to date the database by, see DependencyScanOptions.DBLastUpdate
+	if !lastModified.IsZero() {
+		scan.Scanner.DB.LastUpdate = lastModified.UTC().Format(time.RFC3339)
+	}
+
+	return nil
+}
+
+// convert osv vulnerability with its database severity label and group cvss score
+func osvScanResult(vuln types.OSVVulnerability, maxSeverity string) types.ScanResult {
+	result := types.ScanResult{
+		ID:       vuln.ID,
+		Severity: []types.Severity{},
+		Aliases:  vuln.Aliases,
+	}
+
+	// database severity label (ghsa: LOW, MODERATE, HIGH, CRITICAL)
+	if label, ok := vuln.DatabaseSpecific["severity"].(string); ok && label != "" {
+		result.Severity = append(result.Severity, types.Severity{Method: osvDatabase(vuln.ID), Score: label})
+	}
+	// cvss vectors of the advisory, v4 scores are not calculated so those are left out
+	for _, severity := range vuln.Severity {
+		var version string
+		switch severity.Type {
+		case "CVSS_V2":
+			version = "2.0"
+		case "CVSS_V3":
+			version = cvssVectorVersion(severity.Score)
+		default:
+			continue
+		}
+		score, err := cvssBaseScore(severity.Score)
+		if err != nil {
+			continue
+		}
+		result.Severity = append(result.Severity, types.Severity{
+			Method: fmt.Sprintf("cvss_v%s:%s", version, osvDatabase(vuln.ID)),
+			Score:  fmt.Sprintf("%.1f", score),
+			Vector: severity.Score,
+		})
+	}
+	if maxSeverity != "" {
+		result.Severity = append(result.Severity, types.Severity{Method: "cvss_max", Score: maxSeverity})
+	}
+	return result
+}
+
+// purl types of osv ecosystems
+var osvPURLTypes = map[string]string{
+	"Go":             "golang",
+	"npm":            "npm",
+	"PyPI":           "pypi",
+	"Maven":          "maven",
+	"crates.io":      "cargo",
+	"RubyGems":       "gem",
+	"NuGet":          "nuget",
+	"Packagist":      "composer",
+	"Pub":            "pub",
+	"Hex":            "hex",
+	"SwiftURL":       "swift",
+	"GitHub Actions": "github",
+	"Alpine":         "apk",
+	"Debian":         "deb",
+	"Ubuntu":         "deb",
+}
+
+// purl of an osv package, empty for ecosystems without a purl type
+// (Go golang.org/x/net 0.22.0 -> pkg:golang/golang.org/x/net@v0.22.0)
+func osvPURL(pkg types.OSVPackage) string {
+	// distro ecosystems carry a release suffix (Debian:12)
+	ecosystem, _, _ := strings.Cut(pkg.Ecosystem, ":")
+	purlType, ok := osvPURLTypes[ecosystem]
+	if !ok || pkg.Name == "" {
+		return ""
+	}
+
+	// maven names are group:artifact, distro packages are namespaced by distro
+	name := pkg.Name
+	switch purlType {
+	case "maven":
+		name = strings.Replace(name, ":", "/", 1)
+	case "apk", "deb":
+		name = strings.ToLower(ecosystem) + "/" + name
+	}
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+	}
+
+	// osv records go module versions without their v prefix
+	version := pkg.Version
+	if purlType == "golang" && version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	purl := "pkg:" + purlType + "/" + strings.Join(segments, "/")
+	if version != "" {
+		purl += "@" + url.PathEscape(version)
+	}
+	return purl
+}
+
+// lowercase database prefix of an osv id (GHSA-xxxx -> ghsa)
+func osvDatabase(id string) string {
+	prefix, _, _ := strings.Cut(id, "-")
+	return strings.ToLower(prefix)
+}
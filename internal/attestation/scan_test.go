@@ -0,0 +1,337 @@
+package attestation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trivy reports do not record their db build time
+var testTrivyDBUpdate = time.Date(2025, 1, 23, 6, 9, 35, 0, time.UTC)
+
+const testTrivyReport = `{
+	"SchemaVersion": 2,
+	"CreatedAt": "2025-01-24T00:18:00.275849+01:00",
+	"ArtifactName": "ghcr.io/test-org/test-repo:latest",
+	"ArtifactType": "container_image",
+	"Trivy": {"Version": "0.58.1"},
+	"Results": [
+		{
+			"Target": "ghcr.io/test-org/test-repo:latest (alpine 3.20.3)",
+			"Class": "os-pkgs",
+			"Type": "alpine",
+			"Vulnerabilities": [
+				{
+					"VulnerabilityID": "CVE-2024-1234",
+					"PkgName": "libcrypto3",
+					"PkgIdentifier": {"PURL": "pkg:apk/alpine/libcrypto3@3.3.2-r0?arch=x86_64&distro=3.20.3"},
+					"InstalledVersion": "3.3.2-r0",
+					"FixedVersion": "3.3.2-r1",
+					"Status": "fixed",
+					"SeveritySource": "nvd",
+					"Severity": "HIGH",
+					"DataSource": {"ID": "alpine", "Name": "Alpine Secdb", "URL": "https://secdb.alpinelinux.org/"},
+					"CVSS": {
+						"nvd": {"V3Vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", "V3Score": 7.5,
+							"V2Vector": "AV:N/AC:L/Au:N/C:P/I:N/A:N", "V2Score": 5},
+						"redhat": {"V3Vector": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N", "V3Score": 5.9}
+					}
+				}
+			]
+		},
+		{
+			"Target": "usr/local/bin/app",
+			"Class": "lang-pkgs",
+			"Type": "gobinary",
+			"Vulnerabilities": [
+				{"VulnerabilityID": "GHSA-xxxx-yyyy-zzzz", "PkgName": "golang.org/x/net", "Severity": "MEDIUM"}
+			]
+		}
+	]
+}`
+
+const testOSVReport = `{
+	"results": [
+		{
+			"source": {"path": "/src/go.mod", "type": "lockfile"},
+			"packages": [
+				{
+					"package": {"name": "golang.org/x/net", "version": "0.22.0", "ecosystem": "Go"},
+					"vulnerabilities": [
+						{
+							"id": "GHSA-4v7x-pqxf-cx7m",
+							"modified": "2025-01-21T18:26:41Z",
+							"aliases": ["CVE-2024-45338", "GO-2024-3333"],
+							"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L"}],
+							"database_specific": {"severity": "MODERATE", "cwe_ids": ["CWE-1333"], "github_reviewed": true}
+						},
+						{
+							"id": "GO-2024-3333",
+							"modified": "2024-12-20T20:37:27Z",
+							"aliases": ["CVE-2024-45338", "GHSA-w32m-9786-jp63"],
+							"database_specific": {"url": "https://pkg.go.dev/vuln/GO-2024-3333"}
+						}
+					],
+					"groups": [{"ids": ["GHSA-4v7x-pqxf-cx7m", "GO-2024-3333"], "max_severity": "5.3"}]
+				}
+			]
+		}
+	]
+}`
+
+func TestConvertScanResults(t *testing.T) {
+	t.Run("detects trivy", func(t *testing.T) {
+		scan := types.NewDependencyScan(DepscanOptions{})
+		require.NoError(t, convertScanResults(ScanFormatAuto, []byte(testTrivyReport), scan))
+
+		assert.Equal(t, "trivy", scan.Scanner.Name)
+		assert.Equal(t, "0.58.1", scan.Scanner.Version)
+		assert.Equal(t, "https://github.com/aquasecurity/trivy/releases/tag/v0.58.1", scan.Scanner.URI)
+		assert.Equal(t, trivyDBRepository, scan.Scanner.DB.URI)
+		assert.Empty(t, scan.Scanner.DB.LastUpdate)
+
+		require.Len(t, scan.Scanner.Result, 2)
+		assert.Equal(t, "CVE-2024-1234", scan.Scanner.Result[0].ID)
+		assert.Equal(t, "https://secdb.alpinelinux.org/", scan.Scanner.Result[0].DataSource)
+		assert.Equal(t, []types.Severity{
+			{Method: "nvd", Score: "HIGH"},
+			{Method: "cvss_v3:nvd", Score: "7.5", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"},
+			{Method: "cvss_v2:nvd", Score: "5.0", Vector: "AV:N/AC:L/Au:N/C:P/I:N/A:N"},
+			{Method: "cvss_v3:redhat", Score: "5.9", Vector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N"},
+		}, scan.Scanner.Result[0].Severity)
+		assert.Equal(t, &types.Package{Name: "libcrypto3", Version: "3.3.2-r0", Type: "alpine",
+			PURL: "pkg:apk/alpine/libcrypto3@3.3.2-r0?arch=x86_64&distro=3.20.3"}, scan.Scanner.Result[0].Package)
+		assert.Equal(t, &types.Fix{State: "fixed", Versions: []string{"3.3.2-r1"}}, scan.Scanner.Result[0].Fix)
+		assert.Equal(t, []types.Severity{{Method: "trivy", Score: "MEDIUM"}}, scan.Scanner.Result[1].Severity)
+		assert.Nil(t, scan.Scanner.Result[1].Fix)
+	})
+
+	t.Run("detects osv-scanner", func(t *testing.T) {
+		scan := types.NewDependencyScan(DepscanOptions{})
+		require.NoError(t, convertScanResults(ScanFormatAuto, []byte(testOSVReport), scan))
+
+		assert.Equal(t, "osv-scanner", scan.Scanner.Name)
+		assert.Equal(t, "https://osv.dev", scan.Scanner.DB.URI)
+		assert.Equal(t, "2025-01-21T18:26:41Z", scan.Scanner.DB.LastUpdate)
+
+		require.Len(t, scan.Scanner.Result, 2)
+		ghsa := scan.Scanner.Result[0]
+		assert.Equal(t, "GHSA-4v7x-pqxf-cx7m", ghsa.ID)
+		assert.Equal(t, []string{"CVE-2024-45338", "GO-2024-3333"}, ghsa.Aliases)
+		assert.Equal(t, []types.Severity{
+			{Method: "ghsa", Score: "MODERATE"},
+			{Method: "cvss_v3.1:ghsa", Score: "5.3", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L"},
+			{Method: "cvss_max", Score: "5.3"},
+		}, ghsa.Severity)
+		assert.Equal(t, &types.Package{Name: "golang.org/x/net", Version: "0.22.0", Type: "Go",
+			PURL: "pkg:golang/golang.org/x/net@v0.22.0"}, ghsa.Package)
+		assert.Equal(t, []types.Severity{{Method: "cvss_max", Score: "5.3"}}, scan.Scanner.Result[1].Severity)
+		assert.Equal(t, ghsa.Package, scan.Scanner.Result[1].Package)
+	})
+
+	t.Run("osv.dev vulns", func(t *testing.T) {
+		scan := types.NewDependencyScan(DepscanOptions{})
+		report := `{"vulns": [{"id": "PYSEC-2024-1", "modified": "2024-05-01T00:00:00Z", "aliases": ["CVE-2024-1"],
+			"database_specific": {"severity": "HIGH"}}]}`
+		require.NoError(t, convertScanResults(ScanFormatOSV, []byte(report), scan))
+		require.Len(t, scan.Scanner.Result, 1)
+		assert.Equal(t, []types.Severity{{Method: "pysec", Score: "HIGH"}}, scan.Scanner.Result[0].Severity)
+	})
+
+	t.Run("osv without advisories", func(t *testing.T) {
+		scan := types.NewDependencyScan(DepscanOptions{})
+		require.NoError(t, convertScanResults(ScanFormatOSV, []byte(`{"results": []}`), scan))
+		assert.Empty(t, scan.Scanner.DB.LastUpdate)
+	})
+
+	t.Run("detects grype", func(t *testing.T) {
+		scan := types.NewDependencyScan(DepscanOptions{})
+		require.NoError(t, convertScanResults(ScanFormatAuto, []byte(`{"descriptor": {"version": "0.87.0"}, "matches": []}`), scan))
+		assert.Equal(t, "grype", scan.Scanner.Name)
+	})
+
+	t.Run("grype related vulnerabilities", func(t *testing.T) {
+		report := `{"descriptor": {"version": "0.87.0"}, "matches": [{
+			"vulnerability": {
+				"id": "GHSA-4v7x-pqxf-cx7m",
+				"dataSource": "https://github.com/advisories/GHSA-4v7x-pqxf-cx7m",
+				"namespace": "github:language:go",
+				"severity": "Medium",
+				"cvss": [{"version": "3.1", "vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L", "metrics": {"baseScore": 5.3}}],
+				"fix": {"versions": [], "state": "not-fixed"}
+			},
+			"relatedVulnerabilities": [{
+				"id": "CVE-2024-45338",
+				"namespace": "nvd:cpe",
+				"severity": "Medium",
+				"cvss": [
+					{"vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L", "metrics": {"baseScore": 5.3}},
+					{"vector": "AV:N/AC:L/Au:N/C:N/I:N/A:P", "metrics": {"baseScore": 5}}
+				]
+			}],
+			"artifact": {"name": "golang.org/x/net", "version": "v0.31.0", "type": "go-module",
+				"purl": "pkg:golang/golang.org/x/net@v0.31.0"}
+		}]}`
+		scan := types.NewDependencyScan(DepscanOptions{})
+		require.NoError(t, convertScanResults(ScanFormatGrype, []byte(report), scan))
+		require.Len(t, scan.Scanner.Result, 1)
+
+		result := scan.Scanner.Result[0]
+		assert.Equal(t, "github:language:go", result.Namespace)
+		assert.Equal(t, []string{"CVE-2024-45338"}, result.Aliases)
+		assert.Equal(t, []types.Severity{
+			{Method: "ghsa", Score: "Medium"},
+			{Method: "cvss_v3.1:ghsa", Score: "5.3", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L"},
+			{Method: "nvd", Score: "Medium"},
+			{Method: "cvss_v3.1:nvd", Score: "5.3", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L"},
+			{Method: "cvss:nvd", Score: "5.0", Vector: "AV:N/AC:L/Au:N/C:N/I:N/A:P"},
+		}, result.Severity)
+		assert.Equal(t, &types.Package{Name: "golang.org/x/net", Version: "v0.31.0", Type: "go-module",
+			PURL: "pkg:golang/golang.org/x/net@v0.31.0"}, result.Package)
+		assert.Equal(t, &types.Fix{State: "not-fixed", Versions: []string{}}, result.Fix)
+	})
+
+	t.Run("unknown report", func(t *testing.T) {
+		scan := types.NewDependencyScan(DepscanOptions{})
+		assert.Error(t, convertScanResults(ScanFormatAuto, []byte(`{"packages": []}`), scan))
+	})
+}
+
+func TestGenerateDepscanTrivy(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	resultsPath := filepath.Join(tmpDir, "trivy.json")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(testTrivyReport), 0600))
+	outputPath := filepath.Join(tmpDir, "depscan.json")
+
+	opts := DepscanOptions{
+		Type:        types.ArtifactTypeContainerImage,
+		SubjectName: "ghcr.io/test-org/test-repo",
+		Digest:      "sha256:test",
+		ResultsPath: resultsPath,
+	}
+	require.ErrorContains(t, GenerateDepscan(opts, OutputOptions{File: outputPath}), "--db-last-update")
+
+	opts.DBLastUpdate = testTrivyDBUpdate
+	require.NoError(t, GenerateDepscan(opts, OutputOptions{File: outputPath, Format: OutputFormatStatement}))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	var statement types.Statement
+	require.NoError(t, json.Unmarshal(data, &statement))
+	var predicate types.DependencyScan
+	require.NoError(t, json.Unmarshal(statement.Predicate, &predicate))
+	assert.Equal(t, "trivy", predicate.Scanner.Name)
+	assert.Equal(t, "2025-01-23T06:09:35Z", predicate.Scanner.DB.LastUpdate)
+	assert.Len(t, predicate.Scanner.Result, 2)
+}
+
+func TestGenerateDepscanTimes(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	resultsPath := filepath.Join(tmpDir, "trivy.json")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(testTrivyReport), 0600))
+	outputPath := filepath.Join(tmpDir, "depscan.json")
+
+	generate := func(t *testing.T, opts DepscanOptions) (types.DependencyScan, error) {
+		opts.Type = types.ArtifactTypeContainerImage
+		opts.SubjectName = "ghcr.io/test-org/test-repo"
+		opts.Digest = "sha256:test"
+		opts.DBLastUpdate = testTrivyDBUpdate
+		if opts.ResultsPath == "" {
+			opts.ResultsPath = resultsPath
+		}
+		var predicate types.DependencyScan
+		if err := GenerateDepscan(opts, OutputOptions{File: outputPath}); err != nil {
+			return predicate, err
+		}
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &predicate))
+		return predicate, nil
+	}
+
+	t.Run("report time", func(t *testing.T) {
+		predicate, err := generate(t, DepscanOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "2025-01-23T23:18:00.275849Z", predicate.Metadata.ScanStartedOn)
+		assert.Equal(t, "2025-01-23T23:18:00.275849Z", predicate.Metadata.ScanFinishedOn)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		predicate, err := generate(t, DepscanOptions{StartedAt: time.Date(2025, 1, 23, 23, 10, 0, 500000000, time.UTC)})
+		require.NoError(t, err)
+		assert.Equal(t, "2025-01-23T23:10:00.5Z", predicate.Metadata.ScanStartedOn)
+		assert.Equal(t, "2025-01-23T23:18:00.275849Z", predicate.Metadata.ScanFinishedOn)
+	})
+
+	t.Run("start after finish", func(t *testing.T) {
+		_, err := generate(t, DepscanOptions{StartedAt: time.Date(2025, 1, 24, 0, 0, 0, 0, time.UTC)})
+		assert.ErrorContains(t, err, "is after scan finish")
+	})
+
+	t.Run("report without times", func(t *testing.T) {
+		osvPath := filepath.Join(tmpDir, "osv.json")
+		require.NoError(t, os.WriteFile(osvPath, []byte(testOSVReport), 0600))
+		before := time.Now()
+		predicate, err := generate(t, DepscanOptions{ResultsPath: osvPath})
+		require.NoError(t, err)
+		startedAt, err := time.Parse(time.RFC3339Nano, predicate.Metadata.ScanStartedOn)
+		require.NoError(t, err)
+		assert.False(t, startedAt.Before(before.Truncate(time.Microsecond)))
+	})
+
+	t.Run("report without times and one explicit time", func(t *testing.T) {
+		osvPath := filepath.Join(tmpDir, "osv.json")
+		require.NoError(t, os.WriteFile(osvPath, []byte(testOSVReport), 0600))
+		scanTime := time.Date(2025, 1, 23, 23, 10, 0, 0, time.UTC)
+
+		predicate, err := generate(t, DepscanOptions{ResultsPath: osvPath, FinishedAt: scanTime})
+		require.NoError(t, err)
+		assert.Equal(t, "2025-01-23T23:10:00Z", predicate.Metadata.ScanStartedOn)
+		assert.Equal(t, "2025-01-23T23:10:00Z", predicate.Metadata.ScanFinishedOn)
+
+		predicate, err = generate(t, DepscanOptions{ResultsPath: osvPath, StartedAt: scanTime})
+		require.NoError(t, err)
+		assert.Equal(t, "2025-01-23T23:10:00Z", predicate.Metadata.ScanStartedOn)
+		assert.Equal(t, "2025-01-23T23:10:00Z", predicate.Metadata.ScanFinishedOn)
+	})
+}
+
+func TestOSVPURL(t *testing.T) {
+	for _, tc := range []struct {
+		pkg  types.OSVPackage
+		want string
+	}{
+		{types.OSVPackage{Name: "@babel/core", Version: "7.0.0", Ecosystem: "npm"}, "pkg:npm/%40babel/core@7.0.0"},
+		{types.OSVPackage{Name: "org.yaml:snakeyaml", Version: "1.33", Ecosystem: "Maven"},
+			"pkg:maven/org.yaml/snakeyaml@1.33"},
+		{types.OSVPackage{Name: "openssl", Version: "3.0.11-1", Ecosystem: "Debian:12"},
+			"pkg:deb/debian/openssl@3.0.11-1"},
+		{types.OSVPackage{Name: "requests", Version: "2.31.0", Ecosystem: "PyPI"}, "pkg:pypi/requests@2.31.0"},
+		{types.OSVPackage{Name: "thing", Version: "1.0", Ecosystem: "Unknown"}, ""},
+	} {
+		assert.Equal(t, tc.want, osvPURL(tc.pkg), tc.pkg.Ecosystem)
+	}
+}
+
+func TestParseScanFormat(t *testing.T) {
+	format, err := ParseScanFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, ScanFormatAuto, format)
+
+	_, err = ParseScanFormat("snyk")
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"autogov-helper/internal/signing"
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+)
+
+// write statement in requested format
+func writeStatement(statement []byte, out OutputOptions) error {
+	switch out.Format {
+	case OutputFormatStatement:
+		return writeOutput(statement, out.File)
+	case OutputFormatDSSE, OutputFormatBundle:
+		output, err := signStatement(statement, out)
+		if err != nil {
+			return err
+		}
+		return writeOutput(output, out.File)
+	default:
+		return fmt.Errorf("unsupported output format %q for statement", out.Format)
+	}
+}
+
+// sign statement with local key into envelope or bundle json
+func signStatement(statement []byte, out OutputOptions) ([]byte, error) {
+	if out.SignKey == "" {
+		return nil, fmt.Errorf("signing key is required for %s output", out.Format)
+	}
+
+	signer, err := signing.LoadPrivateKey(out.SignKey)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := signing.Sign(statement, signing.InTotoPayloadType, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Format != OutputFormatBundle {
+		output, err := envelope.Generate()
+		if err != nil {
+			return nil, errors.WrapError("generate envelope", err)
+		}
+		return output, nil
+	}
+
+	// bundle carries certificate when available, otherwise a key hint
+	var bundle *signing.Bundle
+	if out.SignCert != "" {
+		cert, err := signing.LoadCertificate(out.SignCert, signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		bundle = signing.NewCertificateBundle(envelope, cert)
+	} else {
+		bundle, err = signing.NewPublicKeyBundle(envelope, signer.Public())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := bundle.Generate()
+	if err != nil {
+		return nil, errors.WrapError("generate bundle", err)
+	}
+	return output, nil
+}
+
+// sign existing statement file into dsse envelope or bundle
+func SignStatementFile(inputFile string, out OutputOptions) error {
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return errors.WrapError("read statement file", err)
+	}
+
+	// only in-toto statements are signed
+	var statement types.Statement
+	if err := json.Unmarshal(data, &statement); err != nil {
+		return errors.WrapError("parse statement", err)
+	}
+	if statement.Type != types.StatementTypeURI {
+		return fmt.Errorf("input is not an in-toto statement: _type is %q", statement.Type)
+	}
+
+	if !out.Format.IsSigned() {
+		return fmt.Errorf("output format %q is not a signed format", out.Format)
+	}
+
+	return writeStatement(data, out)
+}
@@ -0,0 +1,155 @@
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"autogov-helper/internal/signing"
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// write ecdsa p-256 test key to dir
+func writeTestKey(t *testing.T, dir string) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	return keyPath
+}
+
+func TestGenerateMetadataDSSE(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	keyPath := writeTestKey(t, tmpDir)
+	outputPath := filepath.Join(tmpDir, "metadata.dsse.json")
+
+	err := GenerateMetadata(createTestOptions(), OutputOptions{
+		File:    outputPath,
+		Format:  OutputFormatDSSE,
+		SignKey: keyPath,
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var envelope signing.Envelope
+	require.NoError(t, json.Unmarshal(data, &envelope))
+	assert.Equal(t, signing.InTotoPayloadType, envelope.PayloadType)
+	require.Len(t, envelope.Signatures, 1)
+	assert.NotEmpty(t, envelope.Signatures[0].Sig)
+
+	payload, err := envelope.DecodePayload()
+	require.NoError(t, err)
+
+	var statement types.Statement
+	require.NoError(t, json.Unmarshal(payload, &statement))
+	assert.Equal(t, types.MetadataPredicateTypeURI, statement.PredicateType)
+}
+
+func TestGenerateDepscanBundle(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	keyPath := writeTestKey(t, tmpDir)
+	resultsPath := filepath.Join(tmpDir, "results.json")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(`{
+		"descriptor": {"version": "0.87.0", "db": {"built": "2025-01-23T01:31:43Z", "schemaVersion": "5"}},
+		"matches": []
+	}`), 0600))
+
+	outputPath := filepath.Join(tmpDir, "depscan.bundle.json")
+	err := GenerateDepscan(DepscanOptions{
+		Type:        types.ArtifactTypeContainerImage,
+		SubjectName: "test-image",
+		Digest:      "sha256:test",
+		ResultsPath: resultsPath,
+	}, OutputOptions{File: outputPath, Format: OutputFormatBundle, SignKey: keyPath})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	bundle, err := signing.ParseBundle(data)
+	require.NoError(t, err)
+	assert.Equal(t, signing.BundleMediaType, bundle.MediaType)
+	assert.NotEmpty(t, bundle.VerificationMaterial.PublicKey.Hint)
+	assert.Equal(t, signing.InTotoPayloadType, bundle.DSSEEnvelope.PayloadType)
+
+	// bundles verify like envelopes
+	_, err = Verify(VerifyOptions{
+		EnvelopePath:  outputPath,
+		KeyPaths:      []string{writeTestPublicKey(t, keyPath)},
+		SubjectDigest: "sha256:test",
+	})
+	assert.NoError(t, err)
+}
+
+func TestSignStatementFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	keyPath := writeTestKey(t, tmpDir)
+
+	t.Run("signs statement", func(t *testing.T) {
+		inputPath := filepath.Join(tmpDir, "statement.json")
+		outputPath := filepath.Join(tmpDir, "statement.dsse.json")
+		statement := []byte(`{
+			"_type": "https://in-toto.io/Statement/v1",
+			"subject": [{"name": "app", "digest": {"sha256": "abc123"}}],
+			"predicateType": "https://example.com/predicate",
+			"predicate": {}
+		}`)
+		require.NoError(t, os.WriteFile(inputPath, statement, 0600))
+
+		out := OutputOptions{File: outputPath, Format: OutputFormatDSSE, SignKey: keyPath}
+		require.NoError(t, SignStatementFile(inputPath, out))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		var envelope signing.Envelope
+		require.NoError(t, json.Unmarshal(data, &envelope))
+		payload, err := envelope.DecodePayload()
+		require.NoError(t, err)
+		assert.Equal(t, statement, payload)
+	})
+
+	t.Run("rejects non statement", func(t *testing.T) {
+		inputPath := filepath.Join(tmpDir, "predicate.json")
+		require.NoError(t, os.WriteFile(inputPath, []byte(`{"artifact": {}}`), 0600))
+
+		err := SignStatementFile(inputPath, OutputOptions{Format: OutputFormatDSSE, SignKey: keyPath})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not an in-toto statement")
+	})
+
+	t.Run("rejects unsigned format", func(t *testing.T) {
+		err := SignStatementFile(filepath.Join(tmpDir, "statement.json"), OutputOptions{Format: OutputFormatStatement})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a signed format")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		err := writeStatement([]byte(`{}`), OutputOptions{Format: OutputFormatDSSE})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signing key is required")
+	})
+}
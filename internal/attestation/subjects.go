@@ -0,0 +1,271 @@
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"autogov-helper/internal/oci"
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+	"autogov-helper/internal/util/fileutil"
+)
+
+// resolve image subjects from names and digests
+//
+// names may embed their digest (name@sha256:...), otherwise digests are
+// paired with names by position or a single digest applies to every name
+func ResolveImageSubjects(names, digests []string) ([]types.Subject, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("at least one subject name is required")
+	}
+	if len(digests) > 1 && len(digests) != len(names) {
+		return nil, fmt.Errorf("got %d subject digests for %d subject names", len(digests), len(names))
+	}
+
+	subjects := make([]types.Subject, 0, len(names))
+	for i, name := range names {
+		name, digest, found := strings.Cut(name, "@")
+		switch {
+		case found:
+		case len(digests) == 1:
+			digest = digests[0]
+		case len(digests) > 1:
+			digest = digests[i]
+		default:
+			return nil, fmt.Errorf("subject digest is required for %s", name)
+		}
+
+		subject, err := types.NewSubject(name, digest)
+		if err != nil {
+			return nil, err
+		}
+		// image references are built as name@digest
+		if subject.ShaDigest() == "" {
+			return nil, fmt.Errorf("image subject %s requires a sha256, sha512 or sha384 digest", name)
+		}
+		subjects = appendSubject(subjects, subject)
+	}
+
+	return subjects, nil
+}
+
+// resolve image subjects from local oci layouts or image tarballs
+//
+// names, when given, are paired with paths by position, select the image of
+// a layout listing several and, without their tag, override the image name
+// found in the layout annotations or docker repo tags; with
+// platforms set, each platform manifest of an image index is added as a
+// subject of the same name annotated with its os and architecture
+func ResolveLocalImageSubjects(paths, names []string, platforms bool) ([]types.Subject, []*oci.Image, error) {
+	if len(names) > 0 && len(names) != len(paths) {
+		return nil, nil, fmt.Errorf("got %d subject names for %d image paths", len(names), len(paths))
+	}
+
+	subjects := make([]types.Subject, 0, len(paths))
+	images := make([]*oci.Image, 0, len(paths))
+	for i, path := range paths {
+		// a name also selects the image of a layout holding several
+		var ref string
+		if len(names) > 0 {
+			ref, _, _ = strings.Cut(names[i], "@")
+		}
+		img, err := oci.Load(path, ref)
+		if err != nil {
+			return nil, nil, errors.WrapErrorf("load image %s", err, path)
+		}
+
+		name := img.Name()
+		if ref != "" {
+			name = ref
+			if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+				name = name[:colon]
+			}
+		}
+		if name == "" {
+			return nil, nil, fmt.Errorf("no image name found in %s, pass --subject-name", path)
+		}
+
+		subject, err := types.NewSubject(name, img.Digest)
+		if err != nil {
+			return nil, nil, err
+		}
+		subjects = appendSubject(subjects, subject)
+		images = append(images, img)
+
+		if !platforms {
+			continue
+		}
+		if len(img.Platforms) == 0 {
+			return nil, nil, fmt.Errorf("%s is not a multi-platform image index", path)
+		}
+		for _, platform := range img.Platforms {
+			subject, err := types.NewSubject(name, platform.Digest)
+			if err != nil {
+				return nil, nil, err
+			}
+			subject.Annotations = platformAnnotations(platform)
+			subjects = appendSubject(subjects, subject)
+		}
+	}
+
+	return subjects, images, nil
+}
+
+// subject annotations describing a platform manifest
+func platformAnnotations(platform oci.Platform) map[string]string {
+	annotations := map[string]string{
+		"platform":     platform.String(),
+		"os":           platform.OS,
+		"architecture": platform.Architecture,
+	}
+	if platform.Variant != "" {
+		annotations["variant"] = platform.Variant
+	}
+	return annotations
+}
+
+// how glob patterns become subjects
+type GlobMode string
+
+const (
+	// one subject per matched file
+	GlobModeEach GlobMode = "each"
+	// one subject named after the pattern with a combined digest
+	GlobModeCombined GlobMode = "combined"
+)
+
+// parse glob mode name
+func ParseGlobMode(name string) (GlobMode, error) {
+	switch GlobMode(name) {
+	case "", GlobModeEach:
+		return GlobModeEach, nil
+	case GlobModeCombined:
+		return GlobModeCombined, nil
+	default:
+		return "", fmt.Errorf("invalid glob mode %q, must be 'each' or 'combined'", name)
+	}
+}
+
+// sources for blob subjects
+type BlobSubjectOptions struct {
+	// paths or glob patterns of subject files and directories
+	Paths []string
+	// digests paired with literal paths by position
+	Digests []string
+	// sha256sum style checksums file, read without rehashing
+	ChecksumsPath string
+	// how glob patterns become subjects, defaults to each
+	GlobMode GlobMode
+	// digest algorithm, symlink policy and filters for directories and patterns
+	Digest fileutil.DigestOptions
+	// optional file receiving per-file manifests of path aware digests
+	ManifestPath string
+}
+
+// resolve blob subjects from paths, glob patterns and checksums files
+//
+// digests, when given, are paired with literal paths by position;
+// otherwise the digest of each matched path is calculated
+func ResolveBlobSubjects(opts BlobSubjectOptions) ([]types.Subject, error) {
+	if len(opts.Paths) == 0 && opts.ChecksumsPath == "" {
+		return nil, fmt.Errorf("at least one subject path or checksums file is required")
+	}
+	if len(opts.Digests) > 0 && len(opts.Digests) != len(opts.Paths) {
+		return nil, fmt.Errorf("got %d subject digests for %d subject paths", len(opts.Digests), len(opts.Paths))
+	}
+
+	var subjects []types.Subject
+	manifests := map[string][]fileutil.ManifestEntry{}
+	for i, pattern := range opts.Paths {
+		if len(opts.Digests) > 0 {
+			if fileutil.IsGlob(pattern) {
+				return nil, fmt.Errorf("subject digest cannot be given for glob pattern %s", pattern)
+			}
+			subject, err := types.NewSubject(pattern, opts.Digests[i])
+			if err != nil {
+				return nil, err
+			}
+			subjects = appendSubject(subjects, subject)
+			continue
+		}
+
+		// combined mode digests the pattern as a whole
+		paths := []string{pattern}
+		if fileutil.IsGlob(pattern) && opts.GlobMode != GlobModeCombined {
+			matches, err := fileutil.ExpandGlobFiltered(pattern, opts.Digest.Filter)
+			if err != nil {
+				return nil, err
+			}
+			paths = matches
+		}
+
+		for _, path := range paths {
+			result, err := fileutil.CalculateDigestWithOptions(path, opts.Digest)
+			if err != nil {
+				return nil, errors.WrapErrorf("calculate digest for %s", err, path)
+			}
+			subjects = appendSubject(subjects, types.Subject{Name: path, Digest: result.Digests})
+			if result.Manifest != nil {
+				manifests[path] = result.Manifest
+			}
+		}
+	}
+
+	if opts.ManifestPath != "" {
+		if err := writeManifests(opts.ManifestPath, manifests); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ChecksumsPath != "" {
+		checksums, err := fileutil.ReadChecksumsFile(opts.ChecksumsPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, checksum := range checksums {
+			subjects = appendSubject(subjects, types.Subject{
+				Name:   checksum.Name,
+				Digest: map[string]string{checksum.Algorithm: checksum.Digest},
+			})
+		}
+	}
+
+	return subjects, nil
+}
+
+// write per-file manifests keyed by subject name
+func writeManifests(path string, manifests map[string][]fileutil.ManifestEntry) error {
+	if len(manifests) == 0 {
+		return fmt.Errorf("digest manifest requires a path aware directory digest algorithm")
+	}
+
+	data, err := json.MarshalIndent(manifests, "", "  ")
+	if err != nil {
+		return errors.WrapError("marshal manifest", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.WrapError("write manifest", err)
+	}
+	return nil
+}
+
+// names of subjects in order
+func SubjectNames(subjects []types.Subject) []string {
+	names := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		names = append(names, subject.Name)
+	}
+	return names
+}
+
+// append subject unless one with the same name and digest exists
+func appendSubject(subjects []types.Subject, subject types.Subject) []types.Subject {
+	for _, s := range subjects {
+		if s.Name == subject.Name && s.PrimaryDigest() == subject.PrimaryDigest() {
+			return subjects
+		}
+	}
+	return append(subjects, subject)
+}
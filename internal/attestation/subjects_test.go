@@ -0,0 +1,337 @@
+package attestation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	"autogov-helper/internal/util/fileutil"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveImageSubjects(t *testing.T) {
+	t.Run("paired digests", func(t *testing.T) {
+		subjects, err := ResolveImageSubjects(
+			[]string{"ghcr.io/org/app", "ghcr.io/org/worker"},
+			[]string{"sha256:aaa", "sha256:bbb"},
+		)
+		require.NoError(t, err)
+		require.Len(t, subjects, 2)
+		assert.Equal(t, "ghcr.io/org/app", subjects[0].Name)
+		assert.Equal(t, "aaa", subjects[0].Digest["sha256"])
+		assert.Equal(t, "ghcr.io/org/worker", subjects[1].Name)
+		assert.Equal(t, "bbb", subjects[1].Digest["sha256"])
+	})
+
+	t.Run("single digest for many names", func(t *testing.T) {
+		subjects, err := ResolveImageSubjects(
+			[]string{"ghcr.io/org/app", "docker.io/org/app"},
+			[]string{"sha256:aaa"},
+		)
+		require.NoError(t, err)
+		require.Len(t, subjects, 2)
+		assert.Equal(t, "aaa", subjects[1].Digest["sha256"])
+	})
+
+	t.Run("embedded digest", func(t *testing.T) {
+		subjects, err := ResolveImageSubjects([]string{"ghcr.io/org/app@sha256:ccc"}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ghcr.io/org/app", subjects[0].Name)
+		assert.Equal(t, "sha256:ccc", subjects[0].PrimaryDigest())
+	})
+
+	t.Run("several algorithms per subject", func(t *testing.T) {
+		subjects, err := ResolveImageSubjects(
+			[]string{"ghcr.io/org/app"},
+			[]string{"sha512:ddd,gitoid:blob:sha256:eee"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"sha512":        "ddd",
+			"gitoid:sha256": "gitoid:blob:sha256:eee",
+		}, subjects[0].Digest)
+		assert.Equal(t, "sha512:ddd", subjects[0].PrimaryDigest())
+		assert.True(t, subjects[0].HasDigest("sha512:DDD"))
+	})
+
+	t.Run("sha digests are ranked", func(t *testing.T) {
+		subjects, err := ResolveImageSubjects([]string{"ghcr.io/org/app"}, []string{"sha384:fff,sha512:ddd,sha256:aaa"})
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:aaa", subjects[0].PrimaryDigest())
+
+		subjects, err = ResolveImageSubjects([]string{"ghcr.io/org/app"}, []string{"sha384:fff,sha512:ddd"})
+		require.NoError(t, err)
+		assert.Equal(t, "sha512:ddd", subjects[0].PrimaryDigest())
+	})
+
+	t.Run("image without sha digest", func(t *testing.T) {
+		_, err := ResolveImageSubjects([]string{"ghcr.io/org/app"}, []string{"gitoid:blob:sha256:eee"})
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		for _, digest := range []string{"md5:abc", "sah256:abc", "gitoid:blob:md5:abc"} {
+			_, err := ResolveImageSubjects([]string{"ghcr.io/org/app"}, []string{digest})
+			assert.Error(t, err, digest)
+		}
+	})
+
+	t.Run("mismatched digest count", func(t *testing.T) {
+		_, err := ResolveImageSubjects(
+			[]string{"a", "b", "c"},
+			[]string{"sha256:aaa", "sha256:bbb"},
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing digest", func(t *testing.T) {
+		_, err := ResolveImageSubjects([]string{"ghcr.io/org/app"}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestResolveLocalImageSubjects(t *testing.T) {
+	var idx v1.ImageIndex = empty.Index
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}},
+		})
+	}
+	indexDigest, err := idx.Digest()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendIndex(idx))
+
+	t.Run("index only", func(t *testing.T) {
+		subjects, images, err := ResolveLocalImageSubjects([]string{dir}, []string{"ghcr.io/org/app"}, false)
+		require.NoError(t, err)
+		require.Len(t, subjects, 1)
+		assert.Equal(t, indexDigest.String(), subjects[0].PrimaryDigest())
+		assert.Len(t, images[0].Platforms, 2)
+	})
+
+	t.Run("platform subjects", func(t *testing.T) {
+		subjects, _, err := ResolveLocalImageSubjects([]string{dir}, []string{"ghcr.io/org/app"}, true)
+		require.NoError(t, err)
+		require.Len(t, subjects, 3)
+		assert.Empty(t, subjects[0].Annotations)
+		for i, arch := range []string{"amd64", "arm64"} {
+			assert.Equal(t, "ghcr.io/org/app", subjects[i+1].Name)
+			assert.Equal(t, "linux/"+arch, subjects[i+1].Annotations["platform"])
+			assert.Equal(t, arch, subjects[i+1].Annotations["architecture"])
+			assert.NotEqual(t, indexDigest.String(), subjects[i+1].PrimaryDigest())
+		}
+	})
+
+	t.Run("missing image name", func(t *testing.T) {
+		_, _, err := ResolveLocalImageSubjects([]string{dir}, nil, false)
+		assert.ErrorContains(t, err, "--subject-name")
+	})
+
+	t.Run("platform subjects need an index", func(t *testing.T) {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+		single := t.TempDir()
+		p, err := layout.Write(single, empty.Index)
+		require.NoError(t, err)
+		require.NoError(t, p.AppendImage(img))
+
+		_, _, err = ResolveLocalImageSubjects([]string{single}, []string{"ghcr.io/org/app"}, true)
+		assert.ErrorContains(t, err, "not a multi-platform image index")
+	})
+}
+
+func TestParseGlobMode(t *testing.T) {
+	mode, err := ParseGlobMode("")
+	require.NoError(t, err)
+	assert.Equal(t, GlobModeEach, mode)
+
+	mode, err = ParseGlobMode("combined")
+	require.NoError(t, err)
+	assert.Equal(t, GlobModeCombined, mode)
+
+	_, err = ParseGlobMode("merged")
+	assert.Error(t, err)
+}
+
+func TestResolveBlobSubjects(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"app-linux-amd64", "app-darwin-arm64", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0600))
+	}
+
+	t.Run("repeated paths", func(t *testing.T) {
+		paths := []string{filepath.Join(tmpDir, "app-linux-amd64"), filepath.Join(tmpDir, "notes.txt")}
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{Paths: paths})
+		require.NoError(t, err)
+		require.Len(t, subjects, 2)
+
+		expected, err := fileutil.CalculateDigest(paths[1])
+		require.NoError(t, err)
+		assert.Equal(t, paths[1], subjects[1].Name)
+		assert.Equal(t, expected, subjects[1].PrimaryDigest())
+	})
+
+	t.Run("glob pattern", func(t *testing.T) {
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{Paths: []string{filepath.Join(tmpDir, "app-*")}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(tmpDir, "app-darwin-arm64"),
+			filepath.Join(tmpDir, "app-linux-amd64"),
+		}, SubjectNames(subjects))
+	})
+
+	t.Run("bracketed filename", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app[1].bin")
+		require.NoError(t, os.WriteFile(path, []byte("app"), 0600))
+
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{Paths: []string{path}})
+		require.NoError(t, err)
+		require.Len(t, subjects, 1)
+		assert.Equal(t, path, subjects[0].Name)
+
+		subjects, err = ResolveBlobSubjects(BlobSubjectOptions{Paths: []string{path}, Digests: []string{"sha256:abc"}})
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:abc", subjects[0].PrimaryDigest())
+	})
+
+	t.Run("combined glob", func(t *testing.T) {
+		pattern := filepath.Join(tmpDir, "app-*")
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{
+			Paths:    []string{pattern},
+			GlobMode: GlobModeCombined,
+		})
+		require.NoError(t, err)
+		require.Len(t, subjects, 1)
+		assert.Equal(t, pattern, subjects[0].Name)
+
+		expected, err := fileutil.CalculateDigest(pattern)
+		require.NoError(t, err)
+		assert.Equal(t, expected, subjects[0].PrimaryDigest())
+	})
+
+	t.Run("duplicates are skipped", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "notes.txt")
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{Paths: []string{path, filepath.Join(tmpDir, "*.txt")}})
+		require.NoError(t, err)
+		assert.Len(t, subjects, 1)
+	})
+
+	t.Run("provided digests", func(t *testing.T) {
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{
+			Paths:   []string{"dist/app"},
+			Digests: []string{"sha256:abc"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "abc", subjects[0].Digest["sha256"])
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		_, err := ResolveBlobSubjects(BlobSubjectOptions{Paths: []string{filepath.Join(tmpDir, "*.jar")}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no files match")
+	})
+
+	t.Run("checksums file", func(t *testing.T) {
+		checksumsPath := filepath.Join(tmpDir, "checksums.txt")
+		require.NoError(t, os.WriteFile(checksumsPath, []byte(
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  app_linux_amd64.tar.gz\n"+
+				"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae *app_darwin_arm64.tar.gz\n",
+		), 0600))
+
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{
+			Paths:         []string{filepath.Join(tmpDir, "notes.txt")},
+			ChecksumsPath: checksumsPath,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(tmpDir, "notes.txt"),
+			"app_linux_amd64.tar.gz",
+			"app_darwin_arm64.tar.gz",
+		}, SubjectNames(subjects))
+		assert.Equal(t,
+			"sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+			subjects[2].PrimaryDigest())
+	})
+
+	t.Run("path aware directory digest", func(t *testing.T) {
+		distDir := t.TempDir()
+		for _, name := range []string{"app", "lib/core.so", "lib/extra.so"} {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(distDir, name)), 0700))
+			require.NoError(t, os.WriteFile(filepath.Join(distDir, name), []byte(name), 0600))
+		}
+
+		manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{
+			Paths:        []string{distDir},
+			Digest:       fileutil.DigestOptions{DirAlgorithm: fileutil.DirAlgorithmMerkle},
+			ManifestPath: manifestPath,
+		})
+		require.NoError(t, err)
+		require.Len(t, subjects, 1)
+		assert.Len(t, subjects[0].Digest[fileutil.DigestKeyMerkle], 64)
+
+		data, err := os.ReadFile(manifestPath)
+		require.NoError(t, err)
+		var manifests map[string][]fileutil.ManifestEntry
+		require.NoError(t, json.Unmarshal(data, &manifests))
+		require.Len(t, manifests[distDir], 3)
+		assert.Equal(t, "lib/core.so", manifests[distDir][1].Path)
+	})
+
+	t.Run("dirhash digests compare exactly", func(t *testing.T) {
+		subjects, err := ResolveBlobSubjects(BlobSubjectOptions{
+			Paths:  []string{tmpDir},
+			Digest: fileutil.DigestOptions{DirAlgorithm: fileutil.DirAlgorithmDirHash},
+		})
+		require.NoError(t, err)
+		digest := subjects[0].PrimaryDigest()
+		assert.True(t, strings.HasPrefix(digest, "dirHash:h1:"))
+		assert.True(t, subjects[0].HasDigest(digest))
+		// base64 is case sensitive, unlike hex
+		swapped := strings.Map(func(r rune) rune {
+			if unicode.IsUpper(r) {
+				return unicode.ToLower(r)
+			}
+			return unicode.ToUpper(r)
+		}, strings.TrimPrefix(digest, "dirHash:h1:"))
+		assert.False(t, subjects[0].HasDigest("dirHash:h1:"+swapped))
+	})
+
+	t.Run("manifest requires path aware digest", func(t *testing.T) {
+		_, err := ResolveBlobSubjects(BlobSubjectOptions{
+			Paths:        []string{tmpDir},
+			ManifestPath: filepath.Join(t.TempDir(), "manifest.json"),
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("no sources", func(t *testing.T) {
+		_, err := ResolveBlobSubjects(BlobSubjectOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("digest for glob", func(t *testing.T) {
+		_, err := ResolveBlobSubjects(BlobSubjectOptions{
+			Paths:   []string{filepath.Join(tmpDir, "*")},
+			Digests: []string{"sha256:abc"},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,105 @@
+package attestation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"autogov-helper/internal/signing"
+	"autogov-helper/internal/util/env"
+	"autogov-helper/internal/util/errors"
+
+	"github.com/google/go-github/v68/github"
+)
+
+// options for uploading bundles to the github attestations api
+type UploadOptions struct {
+	// sigstore bundle json
+	InputPath string
+	// owner/repo, defaults to GITHUB_REPOSITORY
+	Repository string
+}
+
+// attestation stored by the github attestations api
+type UploadResult struct {
+	ID         int64
+	Repository string
+}
+
+// request body of POST /repos/{owner}/{repo}/attestations
+type uploadRequest struct {
+	Bundle json.RawMessage `json:"bundle"`
+}
+
+// upload sigstore bundle to the github attestations api
+func Upload(ctx context.Context, opts UploadOptions) (*UploadResult, error) {
+	data, err := os.ReadFile(opts.InputPath)
+	if err != nil {
+		return nil, errors.WrapError("read bundle file", err)
+	}
+	// the api only stores sigstore bundles
+	if _, err := signing.ParseBundle(data); err != nil {
+		return nil, err
+	}
+
+	owner, repo, err := splitRepository(opts.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newGitHubClient()
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("repos/%s/%s/attestations", owner, repo)
+	req, err := client.NewRequest(http.MethodPost, url, uploadRequest{Bundle: data})
+	if err != nil {
+		return nil, errors.WrapError("create upload request", err)
+	}
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if _, err := client.Do(ctx, req, &created); err != nil {
+		return nil, errors.WrapErrorf("upload attestation to %s/%s", err, owner, repo)
+	}
+
+	return &UploadResult{ID: created.ID, Repository: owner + "/" + repo}, nil
+}
+
+// github api client using token and GITHUB_API_URL from env
+func newGitHubClient() (*github.Client, error) {
+	token, err := env.GetGitHubToken()
+	if err != nil {
+		return nil, fmt.Errorf("GH_TOKEN or GITHUB_TOKEN is required: %w", err)
+	}
+
+	client := github.NewClient(nil).WithAuthToken(token)
+	apiURL := os.Getenv(env.EnvGitHubAPIURL)
+	if apiURL == "" {
+		return client, nil
+	}
+
+	// github enterprise server (https://host/api/v3)
+	client, err = client.WithEnterpriseURLs(apiURL, apiURL)
+	if err != nil {
+		return nil, errors.WrapErrorf("parse %s", err, env.EnvGitHubAPIURL)
+	}
+	return client, nil
+}
+
+// split owner/repo, falling back to GITHUB_REPOSITORY
+func splitRepository(repository string) (string, string, error) {
+	if repository == "" {
+		repository = os.Getenv(env.EnvGitHubRepository)
+	}
+	owner, repo, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("invalid repository %q, expected owner/repo", repository)
+	}
+	return owner, repo, nil
+}
@@ -0,0 +1,97 @@
+package attestation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"autogov-helper/internal/signing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// write signed sigstore bundle for statement to dir
+func writeTestBundle(t *testing.T, dir string, statement []byte) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	envelope, err := signing.Sign(statement, signing.InTotoPayloadType, key)
+	require.NoError(t, err)
+	bundle, err := signing.NewPublicKeyBundle(envelope, key.Public())
+	require.NoError(t, err)
+	data, err := bundle.Generate()
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "bundle.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestUpload(t *testing.T) {
+	var received struct {
+		Bundle signing.Bundle `json:"bundle"`
+	}
+	var authorization string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/repos/org/app/attestations", func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":42}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	t.Setenv("GITHUB_API_URL", server.URL+"/api/v3")
+	t.Setenv("GITHUB_REPOSITORY", "org/app")
+
+	dir := t.TempDir()
+	bundlePath := writeTestBundle(t, dir, testStatement(t, "sha256:abc"))
+
+	t.Run("bundle", func(t *testing.T) {
+		result, err := Upload(context.Background(), UploadOptions{InputPath: bundlePath})
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), result.ID)
+		assert.Equal(t, "org/app", result.Repository)
+		assert.Equal(t, "Bearer test-token", authorization)
+		assert.Equal(t, signing.BundleMediaType, received.Bundle.MediaType)
+		require.NotNil(t, received.Bundle.DSSEEnvelope)
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		_, err := Upload(context.Background(), UploadOptions{InputPath: bundlePath, Repository: "org/other"})
+		assert.ErrorContains(t, err, "failed to upload attestation to org/other")
+	})
+
+	t.Run("statement is rejected", func(t *testing.T) {
+		statementPath := filepath.Join(dir, "statement.json")
+		require.NoError(t, os.WriteFile(statementPath, testStatement(t, "sha256:abc"), 0600))
+
+		_, err := Upload(context.Background(), UploadOptions{InputPath: statementPath})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid repository", func(t *testing.T) {
+		_, err := Upload(context.Background(), UploadOptions{InputPath: bundlePath, Repository: "org"})
+		assert.ErrorContains(t, err, "expected owner/repo")
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "")
+		_, err := Upload(context.Background(), UploadOptions{InputPath: bundlePath})
+		assert.ErrorContains(t, err, "GITHUB_TOKEN")
+	})
+}
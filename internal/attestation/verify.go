@@ -0,0 +1,120 @@
+package attestation
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"autogov-helper/internal/config"
+	"autogov-helper/internal/signing"
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+	"autogov-helper/internal/util/fileutil"
+)
+
+// exit codes for verification failures
+const (
+	ExitCodeBadSignature   = 2
+	ExitCodeDigestMismatch = 3
+	ExitCodeSchemaFailure  = 4
+)
+
+// options for verifying attestations
+type VerifyOptions struct {
+	// dsse envelope or sigstore bundle
+	EnvelopePath  string
+	KeyPaths      []string
+	SubjectPath   string
+	SubjectDigest string
+	// digest options used when hashing subject path
+	Digest fileutil.DigestOptions
+}
+
+// verify dsse signed attestation and return its statement
+func Verify(opts VerifyOptions) (*types.Statement, error) {
+	if opts.SubjectPath == "" && opts.SubjectDigest == "" {
+		return nil, fmt.Errorf("subject path or subject digest is required")
+	}
+
+	data, err := os.ReadFile(opts.EnvelopePath)
+	if err != nil {
+		return nil, errors.WrapError("read envelope", err)
+	}
+
+	envelope, err := signing.ExtractEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// load keys
+	var keys []crypto.PublicKey
+	for _, path := range opts.KeyPaths {
+		loaded, err := signing.LoadPublicKeys(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, loaded...)
+	}
+
+	// check signature over pae
+	if envelope.PayloadType != signing.InTotoPayloadType {
+		return nil, errors.WithExitCode(ExitCodeBadSignature,
+			fmt.Errorf("unexpected payload type %q", envelope.PayloadType))
+	}
+	if err := envelope.Verify(keys); err != nil {
+		return nil, errors.WithExitCode(ExitCodeBadSignature, errors.WrapError("verify signature", err))
+	}
+
+	payload, err := envelope.DecodePayload()
+	if err != nil {
+		return nil, err
+	}
+
+	var statement types.Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, errors.WithExitCode(ExitCodeSchemaFailure, errors.WrapError("parse statement", err))
+	}
+
+	// check subject digest, several digests of one subject are comma separated
+	var digests []string
+	for _, digest := range strings.Split(opts.SubjectDigest, ",") {
+		if digest = strings.TrimSpace(digest); digest != "" {
+			digests = append(digests, digest)
+		}
+	}
+	if opts.SubjectPath != "" {
+		result, err := fileutil.CalculateDigestWithOptions(opts.SubjectPath, opts.Digest)
+		if err != nil {
+			return nil, errors.WrapError("calculate digest", err)
+		}
+		digests = digests[:0]
+		for alg, value := range result.Digests {
+			digests = append(digests, types.FormatDigest(alg, value))
+		}
+	}
+	if !hasSubjectDigest(statement.Subject, digests) {
+		return nil, errors.WithExitCode(ExitCodeDigestMismatch,
+			fmt.Errorf("no subject in statement matches digest %s", strings.Join(digests, ", ")))
+	}
+
+	// validate against schema for predicate type
+	if err := config.ValidateStatementForPredicateType(payload, statement.PredicateType); err != nil {
+		return nil, errors.WithExitCode(ExitCodeSchemaFailure, errors.WrapError("validate statement", err))
+	}
+
+	return &statement, nil
+}
+
+// reports whether any subject has one of the digests
+func hasSubjectDigest(subjects []types.Subject, digests []string) bool {
+	for _, subject := range subjects {
+		for _, digest := range digests {
+			if subject.HasDigest(digest) {
+				return true
+			}
+		}
+	}
+	return false
+}
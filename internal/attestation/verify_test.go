@@ -0,0 +1,130 @@
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"autogov-helper/internal/signing"
+	"autogov-helper/internal/util/errors"
+	"autogov-helper/internal/util/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// write public key for private key at path
+func writeTestPublicKey(t *testing.T, keyPath string) string {
+	t.Helper()
+
+	signer, err := signing.LoadPrivateKey(keyPath)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(signer.Public().(*ecdsa.PublicKey))
+	require.NoError(t, err)
+
+	pubPath := keyPath + ".pub"
+	require.NoError(t, os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return pubPath
+}
+
+// sign statement to envelope file
+func writeTestEnvelope(t *testing.T, dir, keyPath string, statement []byte) string {
+	t.Helper()
+
+	statementPath := filepath.Join(dir, "statement.json")
+	require.NoError(t, os.WriteFile(statementPath, statement, 0600))
+
+	envelopePath := filepath.Join(dir, "envelope.json")
+	out := OutputOptions{File: envelopePath, Format: OutputFormatDSSE, SignKey: keyPath}
+	require.NoError(t, SignStatementFile(statementPath, out))
+	return envelopePath
+}
+
+func TestVerify(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	keyPath := writeTestKey(t, tmpDir)
+	pubPath := writeTestPublicKey(t, keyPath)
+
+	// signed metadata statement for an image
+	envelopePath := filepath.Join(tmpDir, "metadata.dsse.json")
+	require.NoError(t, GenerateMetadata(createTestOptions(), OutputOptions{
+		File:    envelopePath,
+		Format:  OutputFormatDSSE,
+		SignKey: keyPath,
+	}))
+
+	t.Run("valid attestation", func(t *testing.T) {
+		statement, err := Verify(VerifyOptions{
+			EnvelopePath:  envelopePath,
+			KeyPaths:      []string{pubPath},
+			SubjectDigest: "sha256:test",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "ghcr.io/test-org/test-repo", statement.Subject[0].Name)
+	})
+
+	t.Run("comma separated digests", func(t *testing.T) {
+		_, err := Verify(VerifyOptions{
+			EnvelopePath:  envelopePath,
+			KeyPaths:      []string{pubPath},
+			SubjectDigest: "sha512:other, sha256:test",
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		otherDir := t.TempDir()
+		otherPub := writeTestPublicKey(t, writeTestKey(t, otherDir))
+
+		_, err := Verify(VerifyOptions{
+			EnvelopePath:  envelopePath,
+			KeyPaths:      []string{otherPub},
+			SubjectDigest: "sha256:test",
+		})
+		require.Error(t, err)
+		assert.Equal(t, ExitCodeBadSignature, errors.ExitCode(err))
+	})
+
+	t.Run("digest mismatch", func(t *testing.T) {
+		subjectPath := filepath.Join(tmpDir, "artifact.txt")
+		require.NoError(t, os.WriteFile(subjectPath, []byte("artifact"), 0600))
+
+		_, err := Verify(VerifyOptions{
+			EnvelopePath: envelopePath,
+			KeyPaths:     []string{pubPath},
+			SubjectPath:  subjectPath,
+		})
+		require.Error(t, err)
+		assert.Equal(t, ExitCodeDigestMismatch, errors.ExitCode(err))
+	})
+
+	t.Run("schema failure", func(t *testing.T) {
+		dir := t.TempDir()
+		invalidPath := writeTestEnvelope(t, dir, keyPath, []byte(`{
+			"_type": "https://in-toto.io/Statement/v1",
+			"subject": [{"name": "app", "digest": {"sha256": "abc123"}}],
+			"predicateType": "https://in-toto.io/attestation/vulns/v0.2",
+			"predicate": {"scanner": {"name": "grype"}}
+		}`))
+
+		_, err := Verify(VerifyOptions{
+			EnvelopePath:  invalidPath,
+			KeyPaths:      []string{pubPath},
+			SubjectDigest: "sha256:abc123",
+		})
+		require.Error(t, err)
+		assert.Equal(t, ExitCodeSchemaFailure, errors.ExitCode(err))
+	})
+
+	t.Run("missing subject", func(t *testing.T) {
+		_, err := Verify(VerifyOptions{EnvelopePath: envelopePath, KeyPaths: []string{pubPath}})
+		require.Error(t, err)
+		assert.Equal(t, 1, errors.ExitCode(err))
+	})
+}
@@ -0,0 +1,190 @@
+package attestation
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
+)
+
+// openvex document read from a file
+type vexSource struct {
+	// document @id, or its path when it has none
+	ID       string
+	Document types.VEXDocument
+}
+
+// read and validate openvex documents
+func loadVEXDocuments(paths []string) ([]vexSource, error) {
+	sources := make([]vexSource, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.WrapError("read VEX file", err)
+		}
+
+		var doc types.VEXDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, errors.WrapErrorf("parse VEX document %s", err, path)
+		}
+		if err := doc.Validate(); err != nil {
+			return nil, errors.WrapErrorf("validate VEX document %s", err, path)
+		}
+
+		id := doc.ID
+		if id == "" {
+			id = path
+		}
+		sources = append(sources, vexSource{ID: id, Document: doc})
+	}
+	return sources, nil
+}
+
+// annotate scan findings with matching vex statements, dropping resolved ones when suppress is set
+func applyVEX(sources []vexSource, subjects []types.Subject, scan *types.DependencyScan, suppress bool) {
+	result := &types.VEXResult{Documents: make([]string, 0, len(sources))}
+	for _, source := range sources {
+		result.Documents = append(result.Documents, source.ID)
+	}
+
+	findings := make([]types.ScanResult, 0, len(scan.Scanner.Result))
+	for _, finding := range scan.Scanner.Result {
+		finding.VEX = matchVEX(sources, subjects, finding)
+		if suppress && finding.VEX.Resolved() {
+			result.Suppressed = append(result.Suppressed, finding)
+			continue
+		}
+		findings = append(findings, finding)
+	}
+
+	scan.Scanner.Result = findings
+	scan.Metadata.VEX = result
+}
+
+// newest statement about the finding for a subject or its package, nil when none match
+func matchVEX(sources []vexSource, subjects []types.Subject, finding types.ScanResult) *types.VEXStatus {
+	var match *types.VEXStatus
+	var matchTime time.Time
+	for _, source := range sources {
+		for _, statement := range source.Document.Statements {
+			if !vexVulnerabilityMatches(statement.Vulnerability, finding) ||
+				!slices.ContainsFunc(statement.Products, func(product types.VEXProduct) bool {
+					return vexProductMatches(product, subjects, finding.Package)
+				}) {
+				continue
+			}
+
+			// statements without a timestamp inherit the document's, later documents win ties
+			timestamp := statement.Timestamp
+			if timestamp == "" {
+				timestamp = source.Document.Timestamp
+			}
+			statementTime, _ := time.Parse(time.RFC3339, timestamp)
+			if match != nil && statementTime.Before(matchTime) {
+				continue
+			}
+
+			match = &types.VEXStatus{
+				Status:          statement.Status,
+				Justification:   statement.Justification,
+				ImpactStatement: statement.ImpactStatement,
+				ActionStatement: statement.ActionStatement,
+				Document:        source.ID,
+			}
+			matchTime = statementTime
+		}
+	}
+	return match
+}
+
+// reports whether the statement vulnerability is the finding or one of its aliases
+func vexVulnerabilityMatches(vuln types.VEXVulnerability, finding types.ScanResult) bool {
+	ids := append([]string{finding.ID}, finding.Aliases...)
+	names := append([]string{vuln.Name, vuln.ID}, vuln.Aliases...)
+	return slices.ContainsFunc(names, func(name string) bool {
+		return name != "" && slices.ContainsFunc(ids, func(id string) bool {
+			return strings.EqualFold(name, id)
+		})
+	})
+}
+
+// reports whether the product is a scanned subject, narrowed by subcomponents to
+// the finding package, or is the finding package itself
+func vexProductMatches(product types.VEXProduct, subjects []types.Subject, pkg *types.Package) bool {
+	if vexComponentIsSubject(product.VEXComponent, subjects) {
+		if len(product.Subcomponents) == 0 {
+			return true
+		}
+		return slices.ContainsFunc(product.Subcomponents, func(component types.VEXComponent) bool {
+			return vexComponentIsPackage(component, pkg)
+		})
+	}
+	return vexComponentIsPackage(product.VEXComponent, pkg)
+}
+
+// reports whether the component hashes, digest id or oci purl digest match a subject
+func vexComponentIsSubject(component types.VEXComponent, subjects []types.Subject) bool {
+	var digests []string
+	for alg, value := range component.Hashes {
+		digests = append(digests, vexHashAlgorithm(alg)+":"+value)
+	}
+	for _, id := range []string{component.ID, component.Identifiers["purl"]} {
+		if strings.HasPrefix(id, "pkg:oci/") {
+			if _, version := splitPURL(id); version != "" {
+				digests = append(digests, version)
+			}
+		} else if strings.HasPrefix(id, "sha") {
+			digests = append(digests, id)
+		}
+	}
+
+	return slices.ContainsFunc(digests, func(digest string) bool {
+		return slices.ContainsFunc(subjects, func(subject types.Subject) bool {
+			return subject.HasDigest(digest)
+		})
+	})
+}
+
+// reports whether the component purl is the package, a purl without version matches every version
+func vexComponentIsPackage(component types.VEXComponent, pkg *types.Package) bool {
+	if pkg == nil || pkg.PURL == "" {
+		return false
+	}
+	pkgBase, pkgVersion := splitPURL(pkg.PURL)
+	for _, id := range []string{component.ID, component.Identifiers["purl"]} {
+		if !strings.HasPrefix(id, "pkg:") {
+			continue
+		}
+		base, version := splitPURL(id)
+		if strings.EqualFold(base, pkgBase) && (version == "" || version == pkgVersion) {
+			return true
+		}
+	}
+	return false
+}
+
+// purl without qualifiers and subpath, split from its unescaped version
+func splitPURL(purl string) (string, string) {
+	purl, _, _ = strings.Cut(purl, "#")
+	purl, _, _ = strings.Cut(purl, "?")
+	base, version, _ := strings.Cut(purl, "@")
+	if unescaped, err := url.PathUnescape(version); err == nil {
+		version = unescaped
+	}
+	return base, version
+}
+
+// in-toto digest algorithm of an openvex hash algorithm (sha-256 -> sha256)
+func vexHashAlgorithm(alg string) string {
+	switch alg {
+	case "sha-256", "sha-384", "sha-512":
+		return strings.ReplaceAll(alg, "-", "")
+	default:
+		return alg
+	}
+}
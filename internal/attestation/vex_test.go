@@ -0,0 +1,271 @@
+package attestation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testVEXDocument = `{
+	"@context": "https://openvex.dev/ns/v0.2.0",
+	"@id": "https://example.com/vex/test-repo-2025-01",
+	"author": "Security Team <security@example.com>",
+	"timestamp": "2025-01-24T12:00:00Z",
+	"version": 1,
+	"statements": [
+		{
+			"vulnerability": {"name": "CVE-2024-1234"},
+			"products": [{"@id": "pkg:oci/test-repo@sha256%3Atest", "subcomponents": [{"@id": "pkg:apk/alpine/libcrypto3"}]}],
+			"status": "not_affected",
+			"justification": "vulnerable_code_not_in_execute_path",
+			"impact_statement": "TLS renegotiation is disabled"
+		},
+		{
+			"vulnerability": {"name": "GHSA-xxxx-yyyy-zzzz"},
+			"products": [{"@id": "pkg:oci/other-repo@sha256%3Aother"}],
+			"status": "not_affected",
+			"justification": "component_not_present"
+		}
+	]
+}`
+
+func TestMatchVEX(t *testing.T) {
+	var doc types.VEXDocument
+	require.NoError(t, json.Unmarshal([]byte(testVEXDocument), &doc))
+	sources := []vexSource{{ID: doc.ID, Document: doc}}
+	subjects := []types.Subject{{Name: "ghcr.io/test-org/test-repo", Digest: map[string]string{"sha256": "test"}}}
+	libcrypto := &types.Package{Name: "libcrypto3", Version: "3.3.2-r0", PURL: "pkg:apk/alpine/libcrypto3@3.3.2-r0?arch=x86_64"}
+
+	t.Run("subject subcomponent", func(t *testing.T) {
+		status := matchVEX(sources, subjects, types.ScanResult{ID: "CVE-2024-1234", Package: libcrypto})
+		require.NotNil(t, status)
+		assert.Equal(t, "not_affected", status.Status)
+		assert.Equal(t, "vulnerable_code_not_in_execute_path", status.Justification)
+		assert.Equal(t, "TLS renegotiation is disabled", status.ImpactStatement)
+		assert.Equal(t, "https://example.com/vex/test-repo-2025-01", status.Document)
+		assert.True(t, status.Resolved())
+	})
+
+	t.Run("alias", func(t *testing.T) {
+		finding := types.ScanResult{ID: "GHSA-aaaa-bbbb-cccc", Aliases: []string{"cve-2024-1234"}, Package: libcrypto}
+		assert.NotNil(t, matchVEX(sources, subjects, finding))
+	})
+
+	t.Run("other package", func(t *testing.T) {
+		finding := types.ScanResult{ID: "CVE-2024-1234", Package: &types.Package{Name: "openssl", PURL: "pkg:apk/alpine/openssl@3.3.2-r0"}}
+		assert.Nil(t, matchVEX(sources, subjects, finding))
+	})
+
+	t.Run("other subject", func(t *testing.T) {
+		assert.Nil(t, matchVEX(sources, subjects, types.ScanResult{ID: "GHSA-xxxx-yyyy-zzzz"}))
+	})
+
+	t.Run("package product and newest statement", func(t *testing.T) {
+		later := types.VEXDocument{Timestamp: "2025-02-01T00:00:00Z", Statements: []types.VEXStatement{{
+			Vulnerability: types.VEXVulnerability{Name: "CVE-2024-1234"},
+			Products:      []types.VEXProduct{{VEXComponent: types.VEXComponent{Identifiers: map[string]string{"purl": "pkg:apk/alpine/libcrypto3@3.3.2-r0"}}}},
+			Status:        "affected",
+		}}}
+		status := matchVEX(append(sources, vexSource{ID: "later.json", Document: later}), subjects,
+			types.ScanResult{ID: "CVE-2024-1234", Package: libcrypto})
+		require.NotNil(t, status)
+		assert.Equal(t, "affected", status.Status)
+		assert.False(t, status.Resolved())
+	})
+
+	t.Run("subject hash", func(t *testing.T) {
+		product := types.VEXProduct{VEXComponent: types.VEXComponent{Hashes: map[string]string{"sha-256": "TEST"}}}
+		assert.True(t, vexProductMatches(product, subjects, nil))
+	})
+}
+
+func TestLoadVEXDocuments(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	sources, err := loadVEXDocuments([]string{write("valid.json", testVEXDocument)})
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	assert.Len(t, sources[0].Document.Statements, 2)
+
+	_, err = loadVEXDocuments([]string{write("context.json", `{"@context": "https://example.com", "statements": []}`)})
+	assert.Error(t, err)
+
+	_, err = loadVEXDocuments([]string{write("justification.json", `{"@context": "https://openvex.dev/ns/v0.2.0",
+		"statements": [{"vulnerability": {"name": "CVE-1"}, "status": "not_affected"}]}`)})
+	assert.ErrorContains(t, err, "requires a justification")
+}
+
+func TestGenerateDepscanVEX(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	resultsPath := filepath.Join(tmpDir, "trivy.json")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(testTrivyReport), 0600))
+	vexPath := filepath.Join(tmpDir, "test.openvex.json")
+	require.NoError(t, os.WriteFile(vexPath, []byte(testVEXDocument), 0600))
+	outputPath := filepath.Join(tmpDir, "depscan.json")
+
+	opts := DepscanOptions{
+		Type:         types.ArtifactTypeContainerImage,
+		SubjectName:  "ghcr.io/test-org/test-repo",
+		Digest:       "sha256:test",
+		ResultsPath:  resultsPath,
+		DBLastUpdate: testTrivyDBUpdate,
+		VEXPaths:     []string{vexPath},
+		Gate:         &types.ScanGate{FailOn: "high"},
+	}
+	readPredicate := func(t *testing.T) types.DependencyScan {
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		var predicate types.DependencyScan
+		require.NoError(t, json.Unmarshal(data, &predicate))
+		return predicate
+	}
+
+	t.Run("annotates", func(t *testing.T) {
+		// the high finding is not_affected, so the gate passes
+		require.NoError(t, GenerateDepscan(opts, OutputOptions{File: outputPath}))
+
+		predicate := readPredicate(t)
+		require.Len(t, predicate.Scanner.Result, 2)
+		require.NotNil(t, predicate.Scanner.Result[0].VEX)
+		assert.Equal(t, "not_affected", predicate.Scanner.Result[0].VEX.Status)
+		assert.Nil(t, predicate.Scanner.Result[1].VEX)
+		assert.Equal(t, []string{"https://example.com/vex/test-repo-2025-01"}, predicate.Metadata.VEX.Documents)
+		assert.True(t, predicate.Metadata.Gate.Passed)
+	})
+
+	t.Run("suppresses", func(t *testing.T) {
+		opts.SuppressVEX = true
+		require.NoError(t, GenerateDepscan(opts, OutputOptions{File: outputPath, Format: OutputFormatPredicate}))
+
+		predicate := readPredicate(t)
+		require.Len(t, predicate.Scanner.Result, 1)
+		assert.Equal(t, "GHSA-xxxx-yyyy-zzzz", predicate.Scanner.Result[0].ID)
+		require.Len(t, predicate.Metadata.VEX.Suppressed, 1)
+		assert.Equal(t, "vulnerable_code_not_in_execute_path", predicate.Metadata.VEX.Suppressed[0].VEX.Justification)
+	})
+}
+
+func TestGenerateVEX(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	subjects := []types.Subject{{Name: "ghcr.io/test-org/test-repo:v1.0.0", Digest: map[string]string{"sha256": "test"}}}
+	writeTriage := func(t *testing.T, content string) string {
+		path := filepath.Join(tmpDir, "triage.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	t.Run("statement", func(t *testing.T) {
+		triagePath := writeTriage(t, `
+author: Security Team <security@example.com>
+statements:
+  - vulnerability: CVE-2024-1234
+    status: not_affected
+    justification: vulnerable_code_not_in_execute_path
+    impact_statement: TLS renegotiation is disabled
+    subcomponents:
+      - pkg:apk/alpine/libcrypto3
+  - vulnerability: CVE-2024-5678
+    status: affected
+    action_statement: Upgrade to v1.0.1
+`)
+		outputPath := filepath.Join(tmpDir, "vex.json")
+		opts := VEXOptions{Type: types.ArtifactTypeContainerImage, Subjects: subjects, TriagePath: triagePath}
+		require.NoError(t, GenerateVEX(opts, OutputOptions{File: outputPath, Format: OutputFormatStatement}))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		var statement types.Statement
+		require.NoError(t, json.Unmarshal(data, &statement))
+		assert.Equal(t, types.OpenVEXPredicateTypeURI, statement.PredicateType)
+
+		var doc types.VEXDocument
+		require.NoError(t, json.Unmarshal(statement.Predicate, &doc))
+		assert.Equal(t, types.OpenVEXContext, doc.Context)
+		assert.Contains(t, doc.ID, "https://openvex.dev/docs/public/vex-")
+		assert.Equal(t, "Security Team <security@example.com>", doc.Author)
+		require.Len(t, doc.Statements, 2)
+
+		product := doc.Statements[0].Products[0]
+		assert.Equal(t, "pkg:oci/test-repo@sha256%3Atest?repository_url=ghcr.io/test-org/test-repo", product.ID)
+		assert.Equal(t, map[string]string{"sha-256": "test"}, product.Hashes)
+
+		// generated statements apply to depscan findings of the same subjects
+		status := matchVEX([]vexSource{{ID: doc.ID, Document: doc}}, subjects, types.ScanResult{
+			ID:      "CVE-2024-1234",
+			Package: &types.Package{Name: "libcrypto3", PURL: "pkg:apk/alpine/libcrypto3@3.3.2-r0"},
+		})
+		require.NotNil(t, status)
+		assert.Equal(t, "not_affected", status.Status)
+	})
+
+	t.Run("author flag", func(t *testing.T) {
+		triagePath := writeTriage(t, `{"statements": [{"vulnerability": "CVE-2024-1234", "status": "fixed"}]}`)
+		outputPath := filepath.Join(tmpDir, "vex-predicate.json")
+		opts := VEXOptions{Type: types.ArtifactTypeBlob, Subjects: subjects, TriagePath: triagePath, Author: "test-org"}
+		require.NoError(t, GenerateVEX(opts, OutputOptions{File: outputPath}))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		var doc types.VEXDocument
+		require.NoError(t, json.Unmarshal(data, &doc))
+		assert.Equal(t, "test-org", doc.Author)
+		assert.Empty(t, doc.Statements[0].Products[0].ID)
+	})
+
+	t.Run("only sha digests become hashes", func(t *testing.T) {
+		triagePath := writeTriage(t, `{author: a, statements: [{vulnerability: CVE-2024-1234, status: fixed}]}`)
+		outputPath := filepath.Join(tmpDir, "vex-blob.json")
+		blob := types.Subject{Name: "dist", Digest: map[string]string{
+			"sha512":        "abc",
+			"gitoid:sha256": "gitoid:blob:sha256:def",
+		}}
+		opts := VEXOptions{Type: types.ArtifactTypeBlob, Subjects: []types.Subject{blob}, TriagePath: triagePath}
+		require.NoError(t, GenerateVEX(opts, OutputOptions{File: outputPath, Format: OutputFormatStatement}))
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		var statement types.Statement
+		require.NoError(t, json.Unmarshal(data, &statement))
+		var doc types.VEXDocument
+		require.NoError(t, json.Unmarshal(statement.Predicate, &doc))
+		assert.Equal(t, map[string]string{"sha-512": "abc"}, doc.Statements[0].Products[0].Hashes)
+
+		blob.Digest = map[string]string{"dirHash": "h1:abc="}
+		opts.Subjects = []types.Subject{blob}
+		assert.ErrorContains(t, GenerateVEX(opts, OutputOptions{File: outputPath}), "requires a sha256")
+	})
+
+	t.Run("invalid triage", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"no author":     `statements: [{vulnerability: CVE-1, status: fixed}]`,
+			"unknown field": `{author: a, statements: [{vulnerability: CVE-1, status: fixed, severity: high}]}`,
+			"status":        `{author: a, statements: [{vulnerability: CVE-1, status: ignored}]}`,
+			"justification": `{author: a, statements: [{vulnerability: CVE-1, status: not_affected, justification: trust_me}]}`,
+			"affected":      `{author: a, statements: [{vulnerability: CVE-1, status: affected}]}`,
+			"no statements": `author: a`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				opts := VEXOptions{Subjects: subjects, TriagePath: writeTriage(t, content)}
+				assert.Error(t, GenerateVEX(opts, OutputOptions{File: filepath.Join(tmpDir, "invalid.json")}))
+			})
+		}
+	})
+}
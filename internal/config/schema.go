@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 
+	"autogov-helper/internal/types"
 	"autogov-helper/internal/util/env"
 	"autogov-helper/internal/util/errors"
 
@@ -20,6 +21,38 @@ var embeddedMetadataSchema string
 //go:embed schemas/dependency-vulnerability-schema.json
 var embeddedDepscanSchema string
 
+//go:embed schemas/slsa-provenance-schema.json
+var embeddedProvenanceSchema string
+
+//go:embed schemas/spdx-sbom-schema.json
+var embeddedSPDXSchema string
+
+//go:embed schemas/cyclonedx-sbom-schema.json
+var embeddedCycloneDXSchema string
+
+//go:embed schemas/static-analysis-schema.json
+var embeddedStaticAnalysisSchema string
+
+//go:embed schemas/openvex-schema.json
+var embeddedOpenVEXSchema string
+
+// schema names by predicate type
+var predicateSchemas = map[string]string{
+	types.MetadataPredicateTypeURI:       "metadata-schema.json",
+	types.DepscanPredicateTypeURI:        "dependency-vulnerability-schema.json",
+	types.ProvenancePredicateTypeURI:     "slsa-provenance-schema.json",
+	types.SPDXPredicateTypeURI:           "spdx-sbom-schema.json",
+	types.CycloneDXPredicateTypeURI:      "cyclonedx-sbom-schema.json",
+	types.StaticAnalysisPredicateTypeURI: "static-analysis-schema.json",
+	types.OpenVEXPredicateTypeURI:        "openvex-schema.json",
+}
+
+// schemas published by the policy repository, newer predicate types are only embedded
+var remoteSchemas = map[string]bool{
+	"metadata-schema.json":                 true,
+	"dependency-vulnerability-schema.json": true,
+}
+
 // get embedded schema content by name
 func getEmbeddedSchema(schemaName string) string {
 	switch schemaName {
@@ -27,15 +60,26 @@ func getEmbeddedSchema(schemaName string) string {
 		return embeddedMetadataSchema
 	case "dependency-vulnerability-schema.json":
 		return embeddedDepscanSchema
+	case "slsa-provenance-schema.json":
+		return embeddedProvenanceSchema
+	case "spdx-sbom-schema.json":
+		return embeddedSPDXSchema
+	case "cyclonedx-sbom-schema.json":
+		return embeddedCycloneDXSchema
+	case "static-analysis-schema.json":
+		return embeddedStaticAnalysisSchema
+	case "openvex-schema.json":
+		return embeddedOpenVEXSchema
 	default:
 		return ""
 	}
 }
 
-// fetch schema from github or embedded
-func fetchSchemaContent(schemaName string) (string, error) {
-	// try github api first
-	if token, err := env.GetGitHubToken(); err == nil && token != "" {
+// fetch schema from github or embedded, remote is false when only the embedded schema applies
+func fetchSchemaContent(schemaName string, remote bool) (string, error) {
+	// try github api first for schemas the policy repository publishes
+	remote = remote && remoteSchemas[schemaName]
+	if token, err := env.GetGitHubToken(); remote && err == nil && token != "" {
 		cfg, err := Load()
 		if err != nil {
 			return "", errors.WrapError("load config", err)
@@ -67,18 +111,13 @@ func fetchSchemaContent(schemaName string) (string, error) {
 	return "", fmt.Errorf("failed to fetch schema %s: no schema sources available", schemaName)
 }
 
-// validate json against schema
+// validate json against predicate portion of schema
 func ValidateJSON(data []byte, schemaName string) error {
-	schemaContent, err := fetchSchemaContent(schemaName)
+	schema, err := loadSchema(schemaName, true)
 	if err != nil {
 		return err
 	}
 
-	var schema map[string]interface{}
-	if err := json.Unmarshal([]byte(schemaContent), &schema); err != nil {
-		return errors.WrapError("parse schema", err)
-	}
-
 	predicateSchema := schema
 	if props, ok := schema["properties"].(map[string]interface{}); ok {
 		if predicate, ok := props["predicate"].(map[string]interface{}); ok {
@@ -86,7 +125,56 @@ func ValidateJSON(data []byte, schemaName string) error {
 		}
 	}
 
-	schemaData, err := json.Marshal(predicateSchema)
+	return validate(data, predicateSchema)
+}
+
+// validate full in-toto statement json against schema
+func ValidateStatementJSON(data []byte, schemaName string) error {
+	// published schemas require a sha256 subject digest
+	schema, err := loadSchema(schemaName, sha256Subjects(data))
+	if err != nil {
+		return err
+	}
+
+	return validate(data, schema)
+}
+
+// reports whether every statement subject has a sha256 digest
+func sha256Subjects(data []byte) bool {
+	var statement struct {
+		Subject []struct {
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+	}
+	if err := json.Unmarshal(data, &statement); err != nil {
+		return false
+	}
+	for _, subject := range statement.Subject {
+		if _, ok := subject.Digest["sha256"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// fetch and parse schema
+func loadSchema(schemaName string, remote bool) (map[string]interface{}, error) {
+	schemaContent, err := fetchSchemaContent(schemaName, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(schemaContent), &schema); err != nil {
+		return nil, errors.WrapError("parse schema", err)
+	}
+
+	return schema, nil
+}
+
+// validate json against parsed schema
+func validate(data []byte, schema map[string]interface{}) error {
+	schemaData, err := json.Marshal(schema)
 	if err != nil {
 		return errors.WrapError("marshal schema", err)
 	}
@@ -119,3 +207,51 @@ func ValidateMetadata(data []byte) error {
 func ValidateDepscan(data []byte) error {
 	return ValidateJSON(data, "dependency-vulnerability-schema.json")
 }
+
+// validate metadata statement
+func ValidateMetadataStatement(data []byte) error {
+	return ValidateStatementJSON(data, "metadata-schema.json")
+}
+
+// validate depscan statement
+func ValidateDepscanStatement(data []byte) error {
+	return ValidateStatementJSON(data, "dependency-vulnerability-schema.json")
+}
+
+// validate provenance attestation
+func ValidateProvenance(data []byte) error {
+	return ValidateJSON(data, "slsa-provenance-schema.json")
+}
+
+// validate provenance statement
+func ValidateProvenanceStatement(data []byte) error {
+	return ValidateStatementJSON(data, "slsa-provenance-schema.json")
+}
+
+// validate static analysis attestation
+func ValidateStaticAnalysis(data []byte) error {
+	return ValidateJSON(data, "static-analysis-schema.json")
+}
+
+// validate static analysis statement
+func ValidateStaticAnalysisStatement(data []byte) error {
+	return ValidateStatementJSON(data, "static-analysis-schema.json")
+}
+
+// validate predicate against schema for its predicate type
+func ValidateForPredicateType(data []byte, predicateType string) error {
+	schemaName, ok := predicateSchemas[predicateType]
+	if !ok {
+		return fmt.Errorf("no schema available for predicate type %q", predicateType)
+	}
+	return ValidateJSON(data, schemaName)
+}
+
+// validate statement against schema for its predicate type
+func ValidateStatementForPredicateType(data []byte, predicateType string) error {
+	schemaName, ok := predicateSchemas[predicateType]
+	if !ok {
+		return fmt.Errorf("no schema available for predicate type %q", predicateType)
+	}
+	return ValidateStatementJSON(data, schemaName)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"testing"
 
+	"autogov-helper/internal/types"
 	"autogov-helper/internal/util/testutil"
 
 	"github.com/stretchr/testify/assert"
@@ -142,3 +143,67 @@ func TestValidateDepscan(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestValidateStatement(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	t.Run("validates valid depscan statement", func(t *testing.T) {
+		validStatement := []byte(`{
+			"_type": "https://in-toto.io/Statement/v1",
+			"subject": [{"name": "test-image", "digest": {"sha256": "abc123"}}],
+			"predicateType": "https://in-toto.io/attestation/vulns/v0.2",
+			"predicate": {
+				"scanner": {
+					"name": "grype",
+					"uri": "https://github.com/anchore/grype/releases/tag/v0.74.7",
+					"version": "0.74.7",
+					"db": {
+						"uri": "https://toolbox-data.anchore.io/grype/databases/listing.json",
+						"version": "5",
+						"lastUpdate": "2024-01-27T19:48:49Z"
+					},
+					"result": []
+				}
+			}
+		}`)
+
+		err := ValidateDepscanStatement(validStatement)
+		assert.NoError(t, err)
+	})
+
+	t.Run("fails on wrong predicate type", func(t *testing.T) {
+		invalidStatement := []byte(`{
+			"_type": "https://in-toto.io/Statement/v1",
+			"subject": [{"name": "test-image", "digest": {"sha256": "abc123"}}],
+			"predicateType": "https://cosign.sigstore.dev/attestation/v1",
+			"predicate": {"scanner": {}}
+		}`)
+
+		err := ValidateDepscanStatement(invalidStatement)
+		require.Error(t, err)
+	})
+
+	t.Run("subjects without sha256 use embedded schema", func(t *testing.T) {
+		statement := []byte(`{
+			"_type": "https://in-toto.io/Statement/v1",
+			"subject": [{"name": "dist", "digest": {"dirHash": "h1:abc="}}],
+			"predicateType": "https://in-toto.io/attestation/vulns/v0.2",
+			"predicate": {}
+		}`)
+		assert.False(t, sha256Subjects(statement))
+		assert.True(t, sha256Subjects([]byte(`{"subject": [{"digest": {"sha256": "abc", "sha512": "def"}}]}`)))
+		assert.False(t, remoteSchemas[predicateSchemas[types.OpenVEXPredicateTypeURI]])
+	})
+
+	t.Run("fails on unknown predicate type", func(t *testing.T) {
+		err := ValidateStatementForPredicateType([]byte(`{}`), "https://example.com/unknown")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no schema available")
+	})
+
+	t.Run("fails on bare predicate", func(t *testing.T) {
+		err := ValidateMetadataStatement([]byte(`{"artifact": {}}`))
+		require.Error(t, err)
+	})
+}
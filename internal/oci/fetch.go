@@ -0,0 +1,150 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// attestation layer stored in a registry
+type Artifact struct {
+	// manifest holding the layer
+	Reference string
+	MediaType types.MediaType
+	// predicate type from annotations, empty when not recorded
+	PredicateType string
+	Content       []byte
+}
+
+// fetch attestations attached to image through referrers and the attestation tag
+func FetchAttestations(ctx context.Context, image string) (name.Digest, []Artifact, error) {
+	subject, err := ResolveDigest(ctx, image)
+	if err != nil {
+		return name.Digest{}, nil, err
+	}
+	remoteOpts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(Keychain),
+	}
+	repo := subject.Context()
+
+	// falls back to the referrers tag schema index when the api is missing
+	index, err := remote.Referrers(subject, remoteOpts...)
+	if err != nil {
+		return name.Digest{}, nil, fmt.Errorf("failed to list referrers of %s: %w", subject, err)
+	}
+	referrers, err := index.IndexManifest()
+	if err != nil {
+		return name.Digest{}, nil, fmt.Errorf("failed to read referrers of %s: %w", subject, err)
+	}
+
+	var artifacts []Artifact
+	for _, desc := range referrers.Manifests {
+		// signatures, sboms and other artifacts attached to the image
+		if !desc.MediaType.IsImage() || !isAttestationArtifact(desc.ArtifactType) {
+			continue
+		}
+		found, err := fetchManifestLayers(repo.Digest(desc.Digest.String()), remoteOpts)
+		if err != nil {
+			return name.Digest{}, nil, err
+		}
+		for i := range found {
+			if found[i].PredicateType == "" {
+				found[i].PredicateType = desc.Annotations[AnnotationPredicateType]
+			}
+		}
+		artifacts = append(artifacts, found...)
+	}
+
+	hash, err := v1.NewHash(subject.DigestStr())
+	if err != nil {
+		return name.Digest{}, nil, fmt.Errorf("invalid image digest %s: %w", subject.DigestStr(), err)
+	}
+	found, err := fetchManifestLayers(repo.Tag(AttestationTag(hash)), remoteOpts)
+	var terr *transport.Error
+	if err != nil && !(errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound) {
+		return name.Digest{}, nil, err
+	}
+	artifacts = append(artifacts, found...)
+
+	return subject, artifacts, nil
+}
+
+// reports whether a referrer artifact type may hold attestations, referrers
+// listed by their empty config type are checked layer by layer
+func isAttestationArtifact(artifactType string) bool {
+	return artifactType == "" || artifactType == string(MediaTypeEmpty) || isAttestationMediaType(artifactType)
+}
+
+// reports whether media type is an in-toto statement, dsse envelope or sigstore bundle
+func isAttestationMediaType(mediaType string) bool {
+	return mediaType == "application/vnd.in-toto+json" ||
+		mediaType == "application/vnd.dsse.envelope.v1+json" ||
+		strings.HasPrefix(mediaType, "application/vnd.dev.sigstore.bundle")
+}
+
+// download attestation layers of a manifest
+func fetchManifestLayers(ref name.Reference, opts []remote.Option) ([]Artifact, error) {
+	img, err := remote.Image(ref, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attestation manifest %s: %w", ref, err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation manifest %s: %w", ref, err)
+	}
+
+	artifacts := make([]Artifact, 0, len(manifest.Layers))
+	for _, desc := range manifest.Layers {
+		if !isAttestationMediaType(string(desc.MediaType)) {
+			continue
+		}
+		layer, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attestation layer %s: %w", desc.Digest, err)
+		}
+		content, err := readLayer(layer)
+		if err != nil {
+			return nil, err
+		}
+
+		predicateType := desc.Annotations[annotationCosignPredicateType]
+		if predicateType == "" {
+			predicateType = manifest.Annotations[AnnotationPredicateType]
+		}
+		artifacts = append(artifacts, Artifact{
+			Reference:     ref.String(),
+			MediaType:     desc.MediaType,
+			PredicateType: predicateType,
+			Content:       content,
+		})
+	}
+	return artifacts, nil
+}
+
+// read uncompressed layer content up to the metadata size limit
+func readLayer(layer v1.Layer) ([]byte, error) {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attestation layer: %w", err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, maxMetadataSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attestation layer: %w", err)
+	}
+	if len(content) > maxMetadataSize {
+		return nil, fmt.Errorf("attestation layer exceeds %d bytes", maxMetadataSize)
+	}
+	return content, nil
+}
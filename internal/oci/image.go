@@ -0,0 +1,350 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// image reference or tag of an index entry
+	AnnotationRefName = "org.opencontainers.image.ref.name"
+	// full image reference set by containerd and docker save
+	AnnotationImageName = "io.containerd.image.name"
+
+	// largest tar entry kept in memory, manifests and indexes are far smaller
+	maxMetadataSize = 4 << 20
+)
+
+// image resolved from a local oci layout or image tarball
+type Image struct {
+	// prefixed manifest or index digest (e.g. sha256:abc)
+	Digest    string
+	MediaType types.MediaType
+	// reference parts from annotations or repo tags, empty when unknown
+	Registry   string
+	Repository string
+	Tag        string
+	// platform manifests when the image is an index
+	Platforms []Platform
+}
+
+// platform specific manifest of an image index
+type Platform struct {
+	// prefixed manifest digest
+	Digest       string
+	OS           string
+	Architecture string
+	Variant      string
+}
+
+// os/arch[/variant] form of platform
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// image name without tag or digest, empty when unknown
+func (i *Image) Name() string {
+	if i.Repository == "" {
+		return ""
+	}
+	if i.Registry == "" {
+		return i.Repository
+	}
+	return i.Registry + "/" + i.Repository
+}
+
+// set registry, repository and tag from reference
+func (i *Image) setReference(ref string) error {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return fmt.Errorf("invalid image reference %q: %w", ref, err)
+	}
+	i.Registry = parsed.Context().RegistryStr()
+	i.Repository = parsed.Context().RepositoryStr()
+	if tag, ok := parsed.(name.Tag); ok {
+		i.Tag = tag.TagStr()
+	}
+	return nil
+}
+
+// reads files from a layout directory or tarball
+type store interface {
+	readFile(name string) ([]byte, error)
+}
+
+// layout directory on disk
+type dirStore string
+
+func (d dirStore) readFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+// small files of a tarball held in memory
+type tarStore map[string][]byte
+
+func (t tarStore) readFile(name string) ([]byte, error) {
+	data, ok := t[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+	}
+	return data, nil
+}
+
+// load image from oci layout directory, oci archive or docker save tarball
+//
+// ref, an image reference or tag, selects the image of an oci layout whose
+// index lists several by their ref name or image name annotations
+func Load(imagePath, ref string) (*Image, error) {
+	info, err := os.Stat(imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat image path: %w", err)
+	}
+	if info.IsDir() {
+		return loadLayout(dirStore(imagePath), ref)
+	}
+
+	files, err := readTar(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := files["index.json"]; ok {
+		return loadLayout(files, ref)
+	}
+	if _, ok := files["manifest.json"]; ok {
+		return loadDockerArchive(imagePath, files)
+	}
+	return nil, fmt.Errorf("%s is not an oci layout or docker save tarball", imagePath)
+}
+
+// reads metadata and manifest sized files from tarball
+func readTar(tarPath string) (tarStore, error) {
+	f, err := os.Open(tarPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open image tarball: %w", err)
+	}
+	defer f.Close()
+
+	files := tarStore{}
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image tarball: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > maxMetadataSize {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from image tarball: %w", hdr.Name, err)
+		}
+		files[path.Clean(strings.TrimPrefix(hdr.Name, "./"))] = data
+	}
+	return files, nil
+}
+
+// resolve the image referenced by an oci layout index
+func loadLayout(s store, ref string) (*Image, error) {
+	data, err := s.readFile("index.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index.json: %w", err)
+	}
+	index, err := v1.ParseIndexManifest(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse index.json: %w", err)
+	}
+
+	desc, err := selectManifest(index.Manifests, ref)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsImage() && !desc.MediaType.IsIndex() {
+		return nil, fmt.Errorf("unsupported manifest media type %q", desc.MediaType)
+	}
+	data, err = readBlob(s, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	img := &Image{Digest: desc.Digest.String(), MediaType: desc.MediaType}
+	if desc.MediaType.IsIndex() {
+		if img.Platforms, err = parsePlatforms(data); err != nil {
+			return nil, err
+		}
+	}
+	switch ref := desc.Annotations[AnnotationRefName]; {
+	case desc.Annotations[AnnotationImageName] != "":
+		err = img.setReference(desc.Annotations[AnnotationImageName])
+	case strings.Contains(ref, "/"):
+		err = img.setReference(ref)
+	default:
+		// bare ref names are tags
+		img.Tag = ref
+	}
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// index entry of the image named by ref, the only entry when the index lists one
+//
+// ref matches the ref name annotation as a full reference or a bare tag, or
+// the image name annotation as a full reference or a repository
+func selectManifest(manifests []v1.Descriptor, ref string) (v1.Descriptor, error) {
+	switch {
+	case len(manifests) == 1:
+		return manifests[0], nil
+	case len(manifests) == 0:
+		return v1.Descriptor{}, fmt.Errorf("oci layout references no image")
+	case ref == "":
+		return v1.Descriptor{}, fmt.Errorf("oci layout references %d images, select one by its %s annotation",
+			len(manifests), AnnotationRefName)
+	}
+
+	repository, tag := ref, ""
+	if colon := strings.LastIndex(ref, ":"); colon > strings.LastIndex(ref, "/") {
+		repository, tag = ref[:colon], ref[colon+1:]
+	}
+
+	// full reference matches win over tag or repository matches
+	var exact, partial []v1.Descriptor
+	refNames := make([]string, 0, len(manifests))
+	for _, desc := range manifests {
+		refName := desc.Annotations[AnnotationRefName]
+		imageName := desc.Annotations[AnnotationImageName]
+		imageRepository, _, _ := strings.Cut(imageName, "@")
+		if colon := strings.LastIndex(imageRepository, ":"); colon > strings.LastIndex(imageRepository, "/") {
+			imageRepository = imageRepository[:colon]
+		}
+
+		switch {
+		case refName == ref || imageName == ref:
+			exact = append(exact, desc)
+		case tag != "" && refName == tag && (imageName == "" || imageRepository == repository),
+			tag == "" && imageRepository == repository:
+			partial = append(partial, desc)
+		}
+		refNames = append(refNames, refName)
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return v1.Descriptor{}, fmt.Errorf("no image named %s in oci layout, found ref names %v", ref, refNames)
+	default:
+		return v1.Descriptor{}, fmt.Errorf("%d images named %s in oci layout", len(matches), ref)
+	}
+}
+
+// platform manifests listed in image index
+//
+// the index digest covers the listed digests, so platform manifest blobs
+// need not be present in the layout
+func parsePlatforms(data []byte) ([]Platform, error) {
+	index, err := v1.ParseIndexManifest(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image index: %w", err)
+	}
+
+	var platforms []Platform
+	for _, desc := range index.Manifests {
+		// skips nested indexes and attestation manifests without a platform
+		if !desc.MediaType.IsImage() || desc.Platform == nil || desc.Platform.OS == "unknown" {
+			continue
+		}
+		platforms = append(platforms, Platform{
+			Digest:       desc.Digest.String(),
+			OS:           desc.Platform.OS,
+			Architecture: desc.Platform.Architecture,
+			Variant:      desc.Platform.Variant,
+		})
+	}
+	return platforms, nil
+}
+
+// read blob and check it matches its digest
+func readBlob(s store, digest v1.Hash) ([]byte, error) {
+	data, err := s.readFile(path.Join("blobs", digest.Algorithm, digest.Hex))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", digest, err)
+	}
+	actual, _, err := v1.SHA256(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash blob %s: %w", digest, err)
+	}
+	if digest.Algorithm != actual.Algorithm || digest.Hex != actual.Hex {
+		return nil, fmt.Errorf("blob %s does not match its digest", digest)
+	}
+	return data, nil
+}
+
+// docker save manifest.json entry
+type dockerManifest struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// resolve image from legacy docker save tarball
+//
+// the tarball holds no registry manifest, so the digest is the one
+// go-containerregistry based tools (crane, kaniko) produce when pushing it
+func loadDockerArchive(tarPath string, s store) (*Image, error) {
+	data, err := s.readFile("manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest.json: %w", err)
+	}
+	var manifests []dockerManifest
+	if err := json.Unmarshal(data, &manifests); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest.json: %w", err)
+	}
+	if len(manifests) != 1 {
+		return nil, fmt.Errorf("docker save tarball must contain exactly one image, found %d", len(manifests))
+	}
+
+	tarImage, err := tarball.ImageFromPath(tarPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load image tarball: %w", err)
+	}
+	digest, err := tarImage.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate image digest: %w", err)
+	}
+	mediaType, err := tarImage.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image media type: %w", err)
+	}
+
+	img := &Image{Digest: digest.String(), MediaType: mediaType}
+	if len(manifests[0].RepoTags) > 0 {
+		if err := img.setReference(manifests[0].RepoTags[0]); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
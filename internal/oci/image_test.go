@@ -0,0 +1,184 @@
+package oci
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// write image to new oci layout with annotations
+func writeTestLayout(t *testing.T, img v1.Image, annotations map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendImage(img, layout.WithAnnotations(annotations)))
+	return dir
+}
+
+// tar up directory contents
+func tarDir(t *testing.T, dir string) string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "image.tar")
+	f, err := os.Create(out)
+	require.NoError(t, err)
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	defer tw.Close()
+	require.NoError(t, tw.AddFS(os.DirFS(dir)))
+	return out
+}
+
+func TestLoad(t *testing.T) {
+	img, err := random.Image(64, 2)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	t.Run("oci layout directory", func(t *testing.T) {
+		dir := writeTestLayout(t, img, map[string]string{
+			AnnotationImageName: "ghcr.io/org/app:v1.2.3",
+			AnnotationRefName:   "v1.2.3",
+		})
+
+		loaded, err := Load(dir, "")
+		require.NoError(t, err)
+		assert.Equal(t, digest.String(), loaded.Digest)
+		assert.Equal(t, "ghcr.io", loaded.Registry)
+		assert.Equal(t, "org/app", loaded.Repository)
+		assert.Equal(t, "v1.2.3", loaded.Tag)
+		assert.Equal(t, "ghcr.io/org/app", loaded.Name())
+	})
+
+	t.Run("oci archive with bare tag", func(t *testing.T) {
+		dir := writeTestLayout(t, img, map[string]string{AnnotationRefName: "latest"})
+
+		loaded, err := Load(tarDir(t, dir), "")
+		require.NoError(t, err)
+		assert.Equal(t, digest.String(), loaded.Digest)
+		assert.Equal(t, "latest", loaded.Tag)
+		assert.Empty(t, loaded.Name())
+	})
+
+	t.Run("docker save tarball", func(t *testing.T) {
+		tag, err := name.NewTag("registry.example.com/team/app:1.0")
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "image.tar")
+		require.NoError(t, tarball.WriteToFile(path, tag, img))
+
+		loaded, err := Load(path, "")
+		require.NoError(t, err)
+		assert.Equal(t, digest.String(), loaded.Digest)
+		assert.Equal(t, "registry.example.com/team/app", loaded.Name())
+		assert.Equal(t, "1.0", loaded.Tag)
+	})
+
+	t.Run("tampered manifest blob", func(t *testing.T) {
+		dir := writeTestLayout(t, img, nil)
+		blob := filepath.Join(dir, "blobs", digest.Algorithm, digest.Hex)
+		require.NoError(t, os.Chmod(blob, 0600))
+		require.NoError(t, os.WriteFile(blob, []byte("{}"), 0600))
+
+		_, err := Load(dir, "")
+		assert.ErrorContains(t, err, "does not match")
+	})
+
+	t.Run("several images", func(t *testing.T) {
+		dir := writeTestLayout(t, img, nil)
+		p, err := layout.FromPath(dir)
+		require.NoError(t, err)
+		other, err := random.Image(64, 1)
+		require.NoError(t, err)
+		require.NoError(t, p.AppendImage(other))
+
+		_, err = Load(dir, "")
+		assert.ErrorContains(t, err, AnnotationRefName)
+	})
+
+	t.Run("image selected by ref name", func(t *testing.T) {
+		dir := writeTestLayout(t, img, map[string]string{AnnotationRefName: "v1"})
+		p, err := layout.FromPath(dir)
+		require.NoError(t, err)
+		other, err := random.Image(64, 1)
+		require.NoError(t, err)
+		otherDigest, err := other.Digest()
+		require.NoError(t, err)
+		require.NoError(t, p.AppendImage(other, layout.WithAnnotations(map[string]string{
+			AnnotationRefName:   "v2",
+			AnnotationImageName: "ghcr.io/org/app:v2",
+		})))
+
+		loaded, err := Load(dir, "v1")
+		require.NoError(t, err)
+		assert.Equal(t, digest.String(), loaded.Digest)
+
+		loaded, err = Load(dir, "ghcr.io/org/app:v2")
+		require.NoError(t, err)
+		assert.Equal(t, otherDigest.String(), loaded.Digest)
+		assert.Equal(t, "v2", loaded.Tag)
+
+		loaded, err = Load(dir, "ghcr.io/org/app")
+		require.NoError(t, err)
+		assert.Equal(t, otherDigest.String(), loaded.Digest)
+
+		_, err = Load(dir, "v3")
+		assert.ErrorContains(t, err, "no image named v3")
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "file.txt")
+		require.NoError(t, os.WriteFile(path, []byte("hello"), 0600))
+
+		_, err := Load(path, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadIndex(t *testing.T) {
+	var idx v1.ImageIndex = empty.Index
+	for _, platform := range []v1.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		// buildx attestation manifest
+		{OS: "unknown", Architecture: "unknown"},
+	} {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+		idx = mutate.AppendManifests(idx, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &platform},
+		})
+	}
+	digest, err := idx.Digest()
+	require.NoError(t, err)
+	manifest, err := idx.IndexManifest()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendIndex(idx, layout.WithAnnotations(map[string]string{
+		AnnotationImageName: "ghcr.io/org/app:v1",
+	})))
+
+	loaded, err := Load(dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, digest.String(), loaded.Digest)
+	assert.True(t, loaded.MediaType.IsIndex())
+	require.Len(t, loaded.Platforms, 2)
+	assert.Equal(t, manifest.Manifests[0].Digest.String(), loaded.Platforms[0].Digest)
+	assert.Equal(t, "linux/amd64", loaded.Platforms[0].String())
+	assert.Equal(t, "linux/arm64/v8", loaded.Platforms[1].String())
+}
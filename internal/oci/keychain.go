@@ -0,0 +1,30 @@
+package oci
+
+import (
+	"autogov-helper/internal/util/env"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+// github container registry host
+const ghcrRegistry = "ghcr.io"
+
+// registry credentials from docker config, then the github token for ghcr.io
+var Keychain = authn.NewMultiKeychain(authn.DefaultKeychain, githubKeychain{})
+
+// resolves ghcr.io credentials from the github token env vars
+type githubKeychain struct{}
+
+func (githubKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	if r.RegistryStr() != ghcrRegistry {
+		return authn.Anonymous, nil
+	}
+	token, err := env.GetGitHubToken()
+	if err != nil {
+		return authn.Anonymous, nil
+	}
+	return &authn.Basic{
+		Username: env.GetEnvOrDefault(env.EnvGitHubActor, "token"),
+		Password: token,
+	}, nil
+}
@@ -0,0 +1,343 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	// oci 1.1 empty config blob
+	MediaTypeEmpty types.MediaType = "application/vnd.oci.empty.v1+json"
+
+	// predicate type of the attached attestation
+	AnnotationPredicateType = "in-toto.io/predicate-type"
+	// cosign layer annotation holding the predicate type
+	annotationCosignPredicateType = "predicateType"
+
+	// suffix of cosign style attestation tags
+	attestationTagSuffix = ".att"
+)
+
+// how attestations are attached to an image
+type PushMode string
+
+const (
+	// referrers api when the registry supports it, attestation tag otherwise
+	PushModeAuto PushMode = "auto"
+	// artifact manifest with subject; go-containerregistry's remote.Put updates
+	// the referrers tag schema index (sha256-<digest>) when the registry lacks
+	// the referrers api, and the index entry gets the artifact type here
+	PushModeReferrers PushMode = "referrers"
+	// cosign style attestation tag (sha256-<digest>.att)
+	PushModeTag PushMode = "tag"
+)
+
+// parse push mode name
+func ParsePushMode(mode string) (PushMode, error) {
+	switch PushMode(mode) {
+	case "", PushModeAuto:
+		return PushModeAuto, nil
+	case PushModeReferrers, PushModeTag:
+		return PushMode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid push mode %q, must be 'auto', 'referrers' or 'tag'", mode)
+	}
+}
+
+// attestation artifact pushed to a registry
+type PushOptions struct {
+	// image reference (tag or digest) the attestation describes
+	Subject string
+	// attestation content and its media type
+	Content   []byte
+	MediaType types.MediaType
+	// predicate type recorded in annotations
+	PredicateType string
+	Mode          PushMode
+}
+
+// where an attestation was pushed
+type PushResult struct {
+	// pushed manifest reference
+	Reference string
+	// prefixed digest of the subject image manifest
+	SubjectDigest string
+	// mode used after auto detection
+	Mode PushMode
+}
+
+// raw manifest pushed with remote.Put
+type rawManifest struct {
+	data      []byte
+	mediaType types.MediaType
+}
+
+func (r rawManifest) RawManifest() ([]byte, error)        { return r.data, nil }
+func (r rawManifest) MediaType() (types.MediaType, error) { return r.mediaType, nil }
+
+// oci 1.1 image manifest with artifact type
+type artifactManifest struct {
+	SchemaVersion int64             `json:"schemaVersion"`
+	MediaType     types.MediaType   `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        v1.Descriptor     `json:"config"`
+	Layers        []v1.Descriptor   `json:"layers"`
+	Subject       *v1.Descriptor    `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// push attestation as an oci artifact attached to its subject image
+func Push(ctx context.Context, opts PushOptions) (*PushResult, error) {
+	ref, err := name.ParseReference(opts.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject image reference %q: %w", opts.Subject, err)
+	}
+	if opts.MediaType == "" {
+		return nil, fmt.Errorf("attestation media type is required")
+	}
+
+	remoteOpts := []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(Keychain),
+	}
+
+	subject, err := remote.Head(ref, remoteOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve subject image %s: %w", ref, err)
+	}
+	repo := ref.Context()
+
+	mode := opts.Mode
+	if mode == "" || mode == PushModeAuto {
+		supported, err := supportsReferrers(ctx, repo, subject.Digest)
+		if err != nil {
+			return nil, err
+		}
+		mode = PushModeTag
+		if supported {
+			mode = PushModeReferrers
+		}
+	}
+
+	layer := static.NewLayer(opts.Content, opts.MediaType)
+	config := static.NewLayer([]byte("{}"), MediaTypeEmpty)
+	for _, blob := range []v1.Layer{layer, config} {
+		if err := remote.WriteLayer(repo, blob, remoteOpts...); err != nil {
+			return nil, fmt.Errorf("failed to upload attestation blob: %w", err)
+		}
+	}
+	layerDesc, err := describe(layer, opts.MediaType)
+	if err != nil {
+		return nil, err
+	}
+	configDesc, err := describe(config, MediaTypeEmpty)
+	if err != nil {
+		return nil, err
+	}
+	if opts.PredicateType != "" {
+		layerDesc.Annotations = map[string]string{annotationCosignPredicateType: opts.PredicateType}
+	}
+
+	result := &PushResult{SubjectDigest: subject.Digest.String(), Mode: mode}
+	var target name.Reference
+	var manifest artifactManifest
+	switch mode {
+	case PushModeReferrers:
+		manifest = artifactManifest{
+			ArtifactType: string(opts.MediaType),
+			Config:       configDesc,
+			Layers:       []v1.Descriptor{layerDesc},
+			Subject:      &v1.Descriptor{MediaType: subject.MediaType, Size: subject.Size, Digest: subject.Digest},
+		}
+		if opts.PredicateType != "" {
+			manifest.Annotations = map[string]string{AnnotationPredicateType: opts.PredicateType}
+		}
+	case PushModeTag:
+		target = repo.Tag(AttestationTag(subject.Digest))
+		manifest, err = existingAttestations(target, remoteOpts)
+		if err != nil {
+			return nil, err
+		}
+		if manifest.Config.Digest == (v1.Hash{}) {
+			manifest.Config = configDesc
+		}
+		if !hasLayer(manifest.Layers, layerDesc.Digest) {
+			manifest.Layers = append(manifest.Layers, layerDesc)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported push mode %q", mode)
+	}
+
+	manifest.SchemaVersion = 2
+	manifest.MediaType = types.OCIManifestSchema1
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal artifact manifest: %w", err)
+	}
+	if target == nil {
+		digest, _, err := v1.SHA256(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash artifact manifest: %w", err)
+		}
+		target = repo.Digest(digest.String())
+	}
+
+	raw := rawManifest{data: data, mediaType: types.OCIManifestSchema1}
+	if err := remote.Put(target, raw, remoteOpts...); err != nil {
+		return nil, fmt.Errorf("failed to push attestation manifest: %w", err)
+	}
+	if mode == PushModeReferrers {
+		if err := setReferrersTagArtifactType(repo, subject.Digest, manifest.ArtifactType, data, remoteOpts); err != nil {
+			return nil, err
+		}
+	}
+	result.Reference = target.String()
+	return result, nil
+}
+
+// resolve image reference to its digest reference (repo@sha256:...)
+func ResolveDigest(ctx context.Context, image string) (name.Digest, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+	if digest, ok := ref.(name.Digest); ok {
+		return digest, nil
+	}
+	desc, err := remote.Head(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(Keychain))
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("failed to resolve image %s: %w", ref, err)
+	}
+	return ref.Context().Digest(desc.Digest.String()), nil
+}
+
+// cosign style attestation tag for image digest
+func AttestationTag(digest v1.Hash) string {
+	return digest.Algorithm + "-" + digest.Hex + attestationTagSuffix
+}
+
+// descriptor of uploaded blob
+func describe(layer v1.Layer, mediaType types.MediaType) (v1.Descriptor, error) {
+	digest, err := layer.Digest()
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to get blob digest: %w", err)
+	}
+	size, err := layer.Size()
+	if err != nil {
+		return v1.Descriptor{}, fmt.Errorf("failed to get blob size: %w", err)
+	}
+	return v1.Descriptor{MediaType: mediaType, Size: size, Digest: digest}, nil
+}
+
+// manifest already pushed to attestation tag, empty when missing
+func existingAttestations(tag name.Reference, opts []remote.Option) (artifactManifest, error) {
+	desc, err := remote.Get(tag, opts...)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return artifactManifest{}, nil
+		}
+		return artifactManifest{}, fmt.Errorf("failed to get attestation tag %s: %w", tag, err)
+	}
+	var manifest artifactManifest
+	if err := json.Unmarshal(desc.Manifest, &manifest); err != nil {
+		return artifactManifest{}, fmt.Errorf("failed to parse attestation tag manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// set artifact type of the pushed manifest in the referrers tag schema index
+//
+// remote.Put takes the index entry artifact type from the config media type,
+// which is the empty descriptor for artifacts; registries with the referrers
+// api have no such index and are left alone
+func setReferrersTagArtifactType(
+	repo name.Repository, subject v1.Hash, artifactType string, manifest []byte, opts []remote.Option,
+) error {
+	digest, _, err := v1.SHA256(bytes.NewReader(manifest))
+	if err != nil {
+		return fmt.Errorf("failed to hash artifact manifest: %w", err)
+	}
+	tag := repo.Tag(subject.Algorithm + "-" + subject.Hex)
+	desc, err := remote.Get(tag, opts...)
+	if err != nil {
+		var terr *transport.Error
+		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to get referrers tag %s: %w", tag, err)
+	}
+
+	var index v1.IndexManifest
+	if err := json.Unmarshal(desc.Manifest, &index); err != nil {
+		return fmt.Errorf("failed to parse referrers tag index: %w", err)
+	}
+	changed := false
+	for i := range index.Manifests {
+		if index.Manifests[i].Digest == digest && index.Manifests[i].ArtifactType != artifactType {
+			index.Manifests[i].ArtifactType = artifactType
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal referrers tag index: %w", err)
+	}
+	if err := remote.Put(tag, rawManifest{data: data, mediaType: types.OCIImageIndex}, opts...); err != nil {
+		return fmt.Errorf("failed to update referrers tag %s: %w", tag, err)
+	}
+	return nil
+}
+
+// reports whether layers include digest
+func hasLayer(layers []v1.Descriptor, digest v1.Hash) bool {
+	for _, layer := range layers {
+		if layer.Digest == digest {
+			return true
+		}
+	}
+	return false
+}
+
+// probes the registry referrers api for subject digest
+func supportsReferrers(ctx context.Context, repo name.Repository, digest v1.Hash) (bool, error) {
+	auth, err := Keychain.Resolve(repo)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve registry credentials: %w", err)
+	}
+	rt, err := transport.NewWithContext(ctx, repo.Registry, auth, remote.DefaultTransport,
+		[]string{repo.Scope(transport.PullScope)})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to registry: %w", err)
+	}
+
+	url := fmt.Sprintf("%s://%s/v2/%s/referrers/%s",
+		repo.Registry.Scheme(), repo.RegistryStr(), repo.RepositoryStr(), digest)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", string(types.OCIImageIndex))
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to probe referrers api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
@@ -0,0 +1,134 @@
+package oci
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// start in-process registry holding one random image
+func startTestRegistry(t *testing.T, referrers bool) (name.Digest, v1.Hash) {
+	t.Helper()
+	server := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithReferrersSupport(referrers),
+	))
+	t.Cleanup(server.Close)
+
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	tag, err := name.NewTag(strings.TrimPrefix(server.URL, "http://") + "/org/app:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, img))
+	return tag.Context().Digest(digest.String()), digest
+}
+
+func TestPush(t *testing.T) {
+	ctx := context.Background()
+	statement := []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+
+	t.Run("referrers api", func(t *testing.T) {
+		ref, digest := startTestRegistry(t, true)
+
+		result, err := Push(ctx, PushOptions{
+			Subject:       ref.String(),
+			Content:       statement,
+			MediaType:     "application/vnd.in-toto+json",
+			PredicateType: "https://example.com/predicate/v1",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, PushModeReferrers, result.Mode)
+		assert.Equal(t, digest.String(), result.SubjectDigest)
+
+		index, err := remote.Referrers(ref)
+		require.NoError(t, err)
+		manifest, err := index.IndexManifest()
+		require.NoError(t, err)
+		require.Len(t, manifest.Manifests, 1)
+
+		pushed, err := name.ParseReference(result.Reference)
+		require.NoError(t, err)
+		desc, err := remote.Get(pushed)
+		require.NoError(t, err)
+		assert.Contains(t, string(desc.Manifest), `"artifactType":"application/vnd.in-toto+json"`)
+		// artifacts carry the empty config
+		assert.Contains(t, string(desc.Manifest), `"config":{"mediaType":"application/vnd.oci.empty.v1+json"`)
+		assert.Contains(t, string(desc.Manifest), digest.String())
+	})
+
+	t.Run("falls back to attestation tag", func(t *testing.T) {
+		ref, digest := startTestRegistry(t, false)
+
+		for _, content := range [][]byte{statement, []byte(`{"payloadType":"application/vnd.in-toto+json"}`)} {
+			result, err := Push(ctx, PushOptions{
+				Subject:   ref.String(),
+				Content:   content,
+				MediaType: "application/vnd.dsse.envelope.v1+json",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, PushModeTag, result.Mode)
+			assert.True(t, strings.HasSuffix(result.Reference, ":sha256-"+digest.Hex+".att"))
+		}
+
+		img, err := remote.Image(ref.Context().Tag(AttestationTag(digest)))
+		require.NoError(t, err)
+		layers, err := img.Layers()
+		require.NoError(t, err)
+		assert.Len(t, layers, 2)
+	})
+
+	t.Run("forced referrers mode", func(t *testing.T) {
+		ref, _ := startTestRegistry(t, false)
+
+		result, err := Push(ctx, PushOptions{
+			Subject:   ref.String(),
+			Content:   statement,
+			MediaType: "application/vnd.in-toto+json",
+			Mode:      PushModeReferrers,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, PushModeReferrers, result.Mode)
+
+		// registry keeps the oci referrers tag schema index
+		index, err := remote.Referrers(ref)
+		require.NoError(t, err)
+		manifest, err := index.IndexManifest()
+		require.NoError(t, err)
+		require.Len(t, manifest.Manifests, 1)
+		assert.Equal(t, "application/vnd.in-toto+json", manifest.Manifests[0].ArtifactType)
+	})
+
+	t.Run("missing image", func(t *testing.T) {
+		ref, _ := startTestRegistry(t, true)
+
+		_, err := Push(ctx, PushOptions{
+			Subject:   ref.Context().Tag("missing").String(),
+			Content:   statement,
+			MediaType: "application/vnd.in-toto+json",
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestParsePushMode(t *testing.T) {
+	mode, err := ParsePushMode("")
+	require.NoError(t, err)
+	assert.Equal(t, PushModeAuto, mode)
+
+	_, err = ParsePushMode("cosign")
+	assert.Error(t, err)
+}
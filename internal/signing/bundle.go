@@ -0,0 +1,136 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"autogov-helper/internal/util/errors"
+)
+
+const BundleMediaType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+
+// sigstore bundle v0.3 in protobuf json form
+//
+// bundles are signed offline and carry no tlog entry or timestamp, so
+// verifiers must skip transparency log checks (cosign --insecure-ignore-tlog)
+type Bundle struct {
+	MediaType            string               `json:"mediaType"`
+	VerificationMaterial VerificationMaterial `json:"verificationMaterial"`
+	DSSEEnvelope         *Envelope            `json:"dsseEnvelope"`
+}
+
+// key or certificate used to verify bundle signature
+type VerificationMaterial struct {
+	PublicKey   *PublicKeyIdentifier `json:"publicKey,omitempty"`
+	Certificate *X509Certificate     `json:"certificate,omitempty"`
+}
+
+// hint identifying a public key held by the verifier
+type PublicKeyIdentifier struct {
+	Hint string `json:"hint"`
+}
+
+// der encoded certificate
+type X509Certificate struct {
+	RawBytes string `json:"rawBytes"`
+}
+
+// create bundle with public key hint for signer
+func NewPublicKeyBundle(envelope *Envelope, pub crypto.PublicKey) (*Bundle, error) {
+	hint, err := KeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bundle{
+		MediaType: BundleMediaType,
+		VerificationMaterial: VerificationMaterial{
+			PublicKey: &PublicKeyIdentifier{Hint: hint},
+		},
+		DSSEEnvelope: envelope,
+	}, nil
+}
+
+// create bundle with signing certificate
+func NewCertificateBundle(envelope *Envelope, cert *x509.Certificate) *Bundle {
+	return &Bundle{
+		MediaType: BundleMediaType,
+		VerificationMaterial: VerificationMaterial{
+			Certificate: &X509Certificate{
+				RawBytes: base64.StdEncoding.EncodeToString(cert.Raw),
+			},
+		},
+		DSSEEnvelope: envelope,
+	}
+}
+
+// load signing certificate, the first pem block is the leaf
+func LoadCertificate(path string, pub crypto.PublicKey) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapError("read certificate", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate found in %s", path)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.WrapError("parse certificate", err)
+	}
+
+	// certificate must belong to signing key
+	if key, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !key.Equal(pub) {
+		return nil, fmt.Errorf("certificate public key does not match signing key")
+	}
+
+	return cert, nil
+}
+
+// parse json bundle
+func ParseBundle(data []byte) (*Bundle, error) {
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, errors.WrapError("parse bundle", err)
+	}
+	if !strings.HasPrefix(bundle.MediaType, "application/vnd.dev.sigstore.bundle") {
+		return nil, fmt.Errorf("unsupported bundle media type %q", bundle.MediaType)
+	}
+	if bundle.DSSEEnvelope == nil {
+		return nil, fmt.Errorf("bundle does not contain a DSSE envelope")
+	}
+	return &bundle, nil
+}
+
+// read dsse envelope from raw envelope or sigstore bundle json
+func ExtractEnvelope(data []byte) (*Envelope, error) {
+	var probe struct {
+		MediaType string `json:"mediaType"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, errors.WrapError("parse envelope", err)
+	}
+
+	if probe.MediaType != "" {
+		bundle, err := ParseBundle(data)
+		if err != nil {
+			return nil, err
+		}
+		return bundle.DSSEEnvelope, nil
+	}
+
+	return ParseEnvelope(data)
+}
+
+// generate json output
+func (b *Bundle) Generate() ([]byte, error) {
+	return json.MarshalIndent(b, "", "  ")
+}
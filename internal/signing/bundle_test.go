@@ -0,0 +1,79 @@
+package signing
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPublicKeyBundle(t *testing.T) {
+	signer, _ := generateTestKey(t, "ecdsa")
+	envelope, err := Sign([]byte(`{}`), InTotoPayloadType, signer)
+	require.NoError(t, err)
+
+	bundle, err := NewPublicKeyBundle(envelope, signer.Public())
+	require.NoError(t, err)
+	assert.Equal(t, BundleMediaType, bundle.MediaType)
+	assert.Nil(t, bundle.VerificationMaterial.Certificate)
+
+	keyID, err := KeyID(signer.Public())
+	require.NoError(t, err)
+	assert.Equal(t, keyID, bundle.VerificationMaterial.PublicKey.Hint)
+
+	// round trip through json
+	data, err := bundle.Generate()
+	require.NoError(t, err)
+	extracted, err := ExtractEnvelope(data)
+	require.NoError(t, err)
+	assert.Equal(t, envelope, extracted)
+}
+
+func TestNewCertificateBundle(t *testing.T) {
+	signer, _ := generateTestKey(t, "ecdsa")
+	other, _ := generateTestKey(t, "ecdsa")
+
+	// self signed certificate for signing key
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "autogov-helper test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	require.NoError(t, err)
+	certPath := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+
+	cert, err := LoadCertificate(certPath, signer.Public())
+	require.NoError(t, err)
+
+	envelope, err := Sign([]byte(`{}`), InTotoPayloadType, signer)
+	require.NoError(t, err)
+
+	bundle := NewCertificateBundle(envelope, cert)
+	assert.Nil(t, bundle.VerificationMaterial.PublicKey)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(der), bundle.VerificationMaterial.Certificate.RawBytes)
+
+	_, err = LoadCertificate(certPath, other.Public())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match")
+}
+
+func TestParseBundle(t *testing.T) {
+	_, err := ParseBundle([]byte(`{"mediaType": "application/json"}`))
+	assert.Error(t, err)
+
+	_, err = ParseBundle([]byte(`{"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DSSE envelope")
+}
@@ -0,0 +1,166 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"autogov-helper/internal/util/errors"
+)
+
+const InTotoPayloadType = "application/vnd.in-toto+json"
+
+// media type of a dsse envelope stored as a blob
+const EnvelopeMediaType = "application/vnd.dsse.envelope.v1+json"
+
+// dsse envelope
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// dsse signature
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// pre-authentication encoding over payload type and payload
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// sign payload and wrap in dsse envelope
+func Sign(payload []byte, payloadType string, signer crypto.Signer) (*Envelope, error) {
+	keyID, err := KeyID(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := signMessage(signer, PAE(payloadType, payload))
+	if err != nil {
+		return nil, errors.WrapError("sign payload", err)
+	}
+
+	return &Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []Signature{
+			{
+				KeyID: keyID,
+				Sig:   base64.StdEncoding.EncodeToString(sig),
+			},
+		},
+	}, nil
+}
+
+// sign message with algorithm matching key type
+func signMessage(signer crypto.Signer, message []byte) ([]byte, error) {
+	switch signer.Public().(type) {
+	case ed25519.PublicKey:
+		// ed25519 signs the full message
+		return signer.Sign(rand.Reader, message, crypto.Hash(0))
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", signer.Public())
+	}
+}
+
+// verify envelope has a valid signature from one of the keys
+func (e *Envelope) Verify(keys []crypto.PublicKey) error {
+	if len(e.Signatures) == 0 {
+		return fmt.Errorf("envelope has no signatures")
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no public keys to verify with")
+	}
+
+	payload, err := e.DecodePayload()
+	if err != nil {
+		return err
+	}
+	pae := PAE(e.PayloadType, payload)
+
+	for _, sig := range e.Signatures {
+		raw, err := base64.StdEncoding.DecodeString(sig.Sig)
+		if err != nil {
+			continue
+		}
+		for _, key := range keysByHint(keys, sig.KeyID) {
+			if verifyMessage(key, pae, raw) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("no valid signature found for provided keys")
+}
+
+// every key, those matching the unauthenticated keyid hint first
+//
+// signers use different keyid schemes, so the hint never excludes a key
+func keysByHint(keys []crypto.PublicKey, keyID string) []crypto.PublicKey {
+	if keyID == "" {
+		return keys
+	}
+	ordered := make([]crypto.PublicKey, 0, len(keys))
+	var rest []crypto.PublicKey
+	for _, key := range keys {
+		if id, err := KeyID(key); err == nil && id == keyID {
+			ordered = append(ordered, key)
+		} else {
+			rest = append(rest, key)
+		}
+	}
+	return append(ordered, rest...)
+}
+
+// verify message signature with algorithm matching key type
+func verifyMessage(key crypto.PublicKey, message, sig []byte) bool {
+	digest := sha256.Sum256(message)
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, message, sig)
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return false
+	}
+}
+
+// decode base64 payload
+func (e *Envelope) DecodePayload() ([]byte, error) {
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, errors.WrapError("decode payload", err)
+	}
+	return payload, nil
+}
+
+// parse json envelope
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, errors.WrapError("parse envelope", err)
+	}
+	if envelope.PayloadType == "" || envelope.Payload == "" {
+		return nil, fmt.Errorf("invalid envelope: payloadType and payload are required")
+	}
+	return &envelope, nil
+}
+
+// generate json output
+func (e *Envelope) Generate() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
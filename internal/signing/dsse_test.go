@@ -0,0 +1,100 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPAE(t *testing.T) {
+	// test vector from the dsse specification
+	pae := PAE("http://example.com/HelloWorld", []byte("hello world"))
+	assert.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world", string(pae))
+}
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+
+	for _, keyType := range []string{"ecdsa", "ed25519", "rsa"} {
+		t.Run(keyType, func(t *testing.T) {
+			signer, _ := generateTestKey(t, keyType)
+
+			envelope, err := Sign(payload, InTotoPayloadType, signer)
+			require.NoError(t, err)
+			assert.Equal(t, InTotoPayloadType, envelope.PayloadType)
+			require.Len(t, envelope.Signatures, 1)
+
+			keyID, err := KeyID(signer.Public())
+			require.NoError(t, err)
+			assert.Equal(t, keyID, envelope.Signatures[0].KeyID)
+
+			decoded, err := envelope.DecodePayload()
+			require.NoError(t, err)
+			assert.Equal(t, payload, decoded)
+
+			// verify signature over pae with stdlib
+			sig, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+			require.NoError(t, err)
+			pae := PAE(InTotoPayloadType, payload)
+			digest := sha256.Sum256(pae)
+			switch pub := signer.Public().(type) {
+			case *ecdsa.PublicKey:
+				assert.True(t, ecdsa.VerifyASN1(pub, digest[:], sig))
+			case ed25519.PublicKey:
+				assert.True(t, ed25519.Verify(pub, pae, sig))
+			case *rsa.PublicKey:
+				assert.NoError(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig))
+			}
+		})
+	}
+}
+
+func TestEnvelopeVerify(t *testing.T) {
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+	signer, _ := generateTestKey(t, "ecdsa")
+	other, _ := generateTestKey(t, "ed25519")
+
+	envelope, err := Sign(payload, InTotoPayloadType, signer)
+	require.NoError(t, err)
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.NoError(t, envelope.Verify([]crypto.PublicKey{other.Public(), signer.Public()}))
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		assert.Error(t, envelope.Verify([]crypto.PublicKey{other.Public()}))
+	})
+
+	t.Run("keyid is only a hint", func(t *testing.T) {
+		hinted := *envelope
+		hinted.Signatures = []Signature{{KeyID: "other-key-id", Sig: envelope.Signatures[0].Sig}}
+		assert.NoError(t, hinted.Verify([]crypto.PublicKey{signer.Public()}))
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		tampered := *envelope
+		tampered.Payload = base64.StdEncoding.EncodeToString([]byte(`{"_type":"tampered"}`))
+		assert.Error(t, tampered.Verify([]crypto.PublicKey{signer.Public()}))
+	})
+
+	t.Run("tampered payload type", func(t *testing.T) {
+		tampered := *envelope
+		tampered.PayloadType = "application/json"
+		assert.Error(t, tampered.Verify([]crypto.PublicKey{signer.Public()}))
+	})
+}
+
+func TestParseEnvelope(t *testing.T) {
+	_, err := ParseEnvelope([]byte(`{"payloadType": "application/vnd.in-toto+json", "payload": "e30=", "signatures": []}`))
+	assert.NoError(t, err)
+
+	_, err = ParseEnvelope([]byte(`{"signatures": []}`))
+	assert.Error(t, err)
+}
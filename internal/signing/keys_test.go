@@ -0,0 +1,112 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generate pem encoded test key
+func generateTestKey(t *testing.T, keyType string) (crypto.Signer, []byte) {
+	t.Helper()
+
+	var signer crypto.Signer
+	var err error
+	switch keyType {
+	case "ecdsa":
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ed25519":
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	case "rsa":
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	require.NoError(t, err)
+
+	return signer, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	for _, keyType := range []string{"ecdsa", "ed25519", "rsa"} {
+		t.Run(keyType, func(t *testing.T) {
+			expected, data := generateTestKey(t, keyType)
+
+			signer, err := ParsePrivateKey(data)
+			require.NoError(t, err)
+			assert.Equal(t, expected.Public(), signer.Public())
+		})
+	}
+
+	t.Run("sec1 ec key", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		_, err = ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("unsupported curve", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+
+		_, err = ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "P-256")
+	})
+
+	t.Run("not pem", func(t *testing.T) {
+		_, err := ParsePrivateKey([]byte("not a key"))
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	_, data := generateTestKey(t, "ecdsa")
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, data, 0600))
+
+	_, err := LoadPrivateKey(keyPath)
+	assert.NoError(t, err)
+
+	_, err = LoadPrivateKey(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	ecKey, _ := generateTestKey(t, "ecdsa")
+	edKey, _ := generateTestKey(t, "ed25519")
+
+	// build key set of two pem blocks
+	var keySet []byte
+	for _, signer := range []crypto.Signer{ecKey, edKey} {
+		der, err := x509.MarshalPKIXPublicKey(signer.Public())
+		require.NoError(t, err)
+		keySet = append(keySet, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})...)
+	}
+
+	keys, err := ParsePublicKeys(keySet)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, ecKey.Public(), keys[0])
+	assert.Equal(t, edKey.Public(), keys[1])
+
+	_, err = ParsePublicKeys([]byte("no keys here"))
+	assert.Error(t, err)
+}
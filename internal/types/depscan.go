@@ -13,7 +13,11 @@ type DependencyScan struct {
 	SubjectName string       `json:"-"`
 	SubjectPath string       `json:"-"`
 	Digest      string       `json:"-"`
-	Scanner     struct {
+	Subjects    []Subject    `json:"-"`
+	// scan window recorded by the scanner report, zero when unknown
+	ReportStartedAt  time.Time `json:"-"`
+	ReportFinishedAt time.Time `json:"-"`
+	Scanner          struct {
 		Name    string `json:"name"`
 		URI     string `json:"uri"`
 		Version string `json:"version"`
@@ -27,6 +31,10 @@ type DependencyScan struct {
 	Metadata struct {
 		ScanStartedOn  string `json:"scanStartedOn"`
 		ScanFinishedOn string `json:"scanFinishedOn"`
+		// vex documents applied to the findings
+		VEX *VEXResult `json:"vex,omitempty"`
+		// decision of the vulnerability gate, when one is configured
+		Gate *GateResult `json:"gate,omitempty"`
 	} `json:"metadata,omitempty"`
 }
 
@@ -35,55 +43,125 @@ func (s *DependencyScan) Generate() ([]byte, error) {
 	return json.MarshalIndent(s, "", "  ")
 }
 
+// generates in-toto statement json wrapping predicate
+func (s *DependencyScan) GenerateStatement() ([]byte, error) {
+	predicate, err := s.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := s.StatementSubjects()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStatement(DepscanPredicateTypeURI, subjects, predicate).Generate()
+}
+
+// subjects of the scan, or one built from subject name or path and digest
+func (s *DependencyScan) StatementSubjects() ([]Subject, error) {
+	if len(s.Subjects) > 0 {
+		return s.Subjects, nil
+	}
+
+	name := s.SubjectName
+	if s.Type == ArtifactTypeBlob {
+		name = s.SubjectPath
+	}
+	subject, err := NewSubject(name, s.Digest)
+	if err != nil {
+		return nil, err
+	}
+	return []Subject{subject}, nil
+}
+
 // options for creating a new scan
 type DependencyScanOptions struct {
 	Type        ArtifactType
 	SubjectName string
 	SubjectPath string
 	Digest      string
+	Subjects    []Subject
 	ResultsPath string
-	StartedAt   time.Time
-	FinishedAt  time.Time
+	// scanner report format (auto, grype, trivy, osv or sarif)
+	Format string
+	// openvex documents annotating findings
+	VEXPaths []string
+	// drop not_affected and fixed findings instead of annotating them
+	SuppressVEX bool
+	// optional vulnerability budget checked after the scan
+	Gate *ScanGate
+	// scan window overriding the times recorded in the report
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// vulnerability db build time overriding the report, required for trivy
+	DBLastUpdate time.Time
 }
 
-// grype scan results
-type GrypeResult struct {
-	Descriptor struct {
-		Version       string `json:"version"`
-		Timestamp     string `json:"timestamp"`
-		Configuration struct {
-			DB struct {
-				UpdateURL string `json:"update-url"`
-			} `json:"db"`
-		} `json:"configuration"`
-		DB struct {
-			Built         string      `json:"built"`
-			SchemaVersion json.Number `json:"schemaVersion"`
-		} `json:"db"`
-	} `json:"descriptor"`
-	Matches []struct {
-		Vulnerability struct {
-			ID       string `json:"id"`
-			Severity string `json:"severity"`
-			CVSS     []struct {
-				Metrics struct {
-					BaseScore float64 `json:"baseScore"`
-				} `json:"metrics"`
-			} `json:"cvss"`
-		} `json:"vulnerability"`
-	} `json:"matches"`
+// severity levels a gate can budget, most severe first
+var GateSeverities = []string{"critical", "high", "medium", "low"}
+
+// vulnerability budget a scan must stay within
+type ScanGate struct {
+	// lowest severity failing the gate on any finding, empty for none
+	FailOn string
+	// findings allowed per severity level, levels not set are unlimited
+	MaxFindings map[string]int
+	// only count findings with a fix available
+	FixableOnly bool
+}
+
+// gate decision recorded in the predicate metadata
+type GateResult struct {
+	Passed      bool   `json:"passed"`
+	FailOn      string `json:"failOn,omitempty"`
+	FixableOnly bool   `json:"fixableOnly,omitempty"`
+	// findings allowed per severity level, unlimited levels are omitted
+	Budget map[string]int `json:"budget"`
+	// findings counted per severity level
+	Counts     map[string]int `json:"counts"`
+	Violations []string       `json:"violations,omitempty"`
 }
 
 // single vulnerability finding
 type ScanResult struct {
 	ID       string     `json:"id"`
 	Severity []Severity `json:"severity"`
+	// other ids of the same vulnerability (cve, ghsa)
+	Aliases []string `json:"aliases,omitempty"`
+	// rule tags reported by sarif tools (security, cwe, ...)
+	Tags []string `json:"tags,omitempty"`
+	// affected package, when the scanner reports it
+	Package *Package `json:"package,omitempty"`
+	// fix availability, when the scanner reports it
+	Fix *Fix `json:"fix,omitempty"`
+	// advisory url and namespace of the vulnerability db entry
+	DataSource string `json:"dataSource,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	// vex statement applied to the finding
+	VEX *VEXStatus `json:"vex,omitempty"`
 }
 
 // vulnerability severity score
 type Severity struct {
 	Method string `json:"method"`
 	Score  string `json:"score"`
+	// cvss vector of the score
+	Vector string `json:"vector,omitempty"`
+}
+
+// package a vulnerability was found in
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Type    string `json:"type,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+// fix state (fixed, not-fixed, wont-fix, unknown) and fixed versions
+type Fix struct {
+	State    string   `json:"state"`
+	Versions []string `json:"versions,omitempty"`
 }
 
 // creates new scan instance
@@ -93,14 +171,20 @@ func NewDependencyScan(opts DependencyScanOptions) *DependencyScan {
 		SubjectName: opts.SubjectName,
 		SubjectPath: opts.SubjectPath,
 		Digest:      opts.Digest,
+		Subjects:    opts.Subjects,
 	}
 
 	// initialize empty result array
 	scan.Scanner.Result = make([]ScanResult, 0)
 
 	// set metadata timestamps
-	scan.Metadata.ScanStartedOn = opts.StartedAt.Format("2006-01-02T15:04:05Z")
-	scan.Metadata.ScanFinishedOn = opts.FinishedAt.Format("2006-01-02T15:04:05Z")
+	scan.SetScanTimes(opts.StartedAt, opts.FinishedAt)
 
 	return scan
 }
+
+// set metadata timestamps in utc with sub-second precision
+func (s *DependencyScan) SetScanTimes(startedAt, finishedAt time.Time) {
+	s.Metadata.ScanStartedOn = startedAt.UTC().Format(time.RFC3339Nano)
+	s.Metadata.ScanFinishedOn = finishedAt.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,62 @@
+package types
+
+import "encoding/json"
+
+// grype scan results
+type GrypeResult struct {
+	Descriptor struct {
+		Version       string `json:"version"`
+		Timestamp     string `json:"timestamp"`
+		Configuration struct {
+			DB struct {
+				UpdateURL string `json:"update-url"`
+			} `json:"db"`
+		} `json:"configuration"`
+		DB struct {
+			Built         string      `json:"built"`
+			SchemaVersion json.Number `json:"schemaVersion"`
+		} `json:"db"`
+	} `json:"descriptor"`
+	Matches []GrypeMatch `json:"matches"`
+}
+
+// vulnerability matched against a package
+type GrypeMatch struct {
+	Vulnerability struct {
+		GrypeVulnerability
+		Fix struct {
+			Versions []string `json:"versions"`
+			State    string   `json:"state"`
+		} `json:"fix"`
+	} `json:"vulnerability"`
+	// same vulnerability in other dbs (nvd entry of a ghsa or distro advisory)
+	RelatedVulnerabilities []GrypeVulnerability `json:"relatedVulnerabilities"`
+	Artifact               struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Type    string `json:"type"`
+		PURL    string `json:"purl"`
+	} `json:"artifact"`
+}
+
+// vulnerability db entry
+type GrypeVulnerability struct {
+	ID         string `json:"id"`
+	DataSource string `json:"dataSource"`
+	// db namespace, source:kind:... (nvd:cpe, github:language:go, alpine:distro:alpine:3.20)
+	Namespace string      `json:"namespace"`
+	Severity  string      `json:"severity"`
+	CVSS      []GrypeCVSS `json:"cvss"`
+}
+
+// cvss score of a vulnerability
+type GrypeCVSS struct {
+	// cna or vendor that scored it (nvd@nist.gov)
+	Source  string `json:"source"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	Vector  string `json:"vector"`
+	Metrics struct {
+		BaseScore float64 `json:"baseScore"`
+	} `json:"metrics"`
+}
@@ -68,6 +68,11 @@ type Metadata struct {
 	Security struct {
 		Permissions map[string]string `json:"permissions"`
 	} `json:"security"`
+
+	// statement subjects, not part of predicate
+	SubjectName   string    `json:"-"`
+	SubjectDigest string    `json:"-"`
+	Subjects      []Subject `json:"-"`
 }
 
 // metadata creation options
@@ -81,6 +86,9 @@ type Options struct {
 	SubjectPath string
 	Digest      string
 
+	// statement subjects, derived from artifact fields when empty
+	Subjects []Subject
+
 	// repo fields
 	Repository      string
 	RepositoryID    string
@@ -138,9 +146,14 @@ func NewFromOptions(opts Options) *Metadata {
 		m.Artifact.Registry = opts.Registry
 		m.Artifact.FullName = opts.FullName
 		m.Artifact.Digest = opts.Digest
+		// subject name is the image reference without digest
+		m.SubjectName, _, _ = strings.Cut(opts.FullName, "@")
 	case ArtifactTypeBlob:
 		m.Artifact.Path = opts.SubjectPath
+		m.SubjectName = opts.SubjectPath
 	}
+	m.SubjectDigest = opts.Digest
+	m.Subjects = opts.Subjects
 
 	// set repo data
 	m.RepositoryData.Repository = opts.Repository
@@ -193,21 +206,41 @@ func NewFromOptions(opts Options) *Metadata {
 	return m
 }
 
-// add sha256 prefix if missing
-func ensureSHA256Prefix(digest string) string {
-	if !strings.HasPrefix(digest, "sha256:") {
-		return "sha256:" + digest
+// add algorithm prefix if missing, bare digests are sha256
+func ensureDigestPrefix(digest string) string {
+	alg, value, err := ParseDigest(digest)
+	if err != nil {
+		return digest
 	}
-	return digest
+	return FormatDigest(alg, value)
 }
 
 // generate json output
 func (m *Metadata) Generate() ([]byte, error) {
 	// format digest only for container images
 	if m.Artifact.Type == string(ArtifactTypeContainerImage) {
-		m.Artifact.Digest = ensureSHA256Prefix(m.Artifact.Digest)
+		m.Artifact.Digest = ensureDigestPrefix(m.Artifact.Digest)
 	}
 
 	// marshal to json
 	return json.MarshalIndent(m, "", "  ")
 }
+
+// generate in-toto statement json output
+func (m *Metadata) GenerateStatement() ([]byte, error) {
+	predicate, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := m.Subjects
+	if len(subjects) == 0 {
+		subject, err := NewSubject(m.SubjectName, m.SubjectDigest)
+		if err != nil {
+			return nil, err
+		}
+		subjects = []Subject{subject}
+	}
+
+	return NewStatement(MetadataPredicateTypeURI, subjects, predicate).Generate()
+}
@@ -0,0 +1,299 @@
+package types
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// openvex v0.2.0 document context
+	OpenVEXContext = "https://openvex.dev/ns/v0.2.0"
+	// openvex documents are attested under their context
+	OpenVEXPredicateTypeURI = OpenVEXContext
+)
+
+// openvex statement statuses
+const (
+	VEXStatusNotAffected        = "not_affected"
+	VEXStatusAffected           = "affected"
+	VEXStatusFixed              = "fixed"
+	VEXStatusUnderInvestigation = "under_investigation"
+)
+
+var vexStatuses = []string{VEXStatusNotAffected, VEXStatusAffected, VEXStatusFixed, VEXStatusUnderInvestigation}
+
+// openvex not_affected justifications
+var vexJustifications = []string{
+	"component_not_present",
+	"vulnerable_code_not_present",
+	"vulnerable_code_not_in_execute_path",
+	"vulnerable_code_cannot_be_controlled_by_adversary",
+	"inline_mitigations_already_exist",
+}
+
+// openvex document
+type VEXDocument struct {
+	Context     string         `json:"@context"`
+	ID          string         `json:"@id"`
+	Author      string         `json:"author"`
+	Role        string         `json:"role,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+	LastUpdated string         `json:"last_updated,omitempty"`
+	Version     int            `json:"version"`
+	Tooling     string         `json:"tooling,omitempty"`
+	Statements  []VEXStatement `json:"statements"`
+
+	// statement subjects, not part of predicate
+	Subjects []Subject `json:"-"`
+}
+
+// status of a vulnerability in a set of products
+type VEXStatement struct {
+	Vulnerability   VEXVulnerability `json:"vulnerability"`
+	Products        []VEXProduct     `json:"products,omitempty"`
+	Status          string           `json:"status"`
+	StatusNotes     string           `json:"status_notes,omitempty"`
+	Justification   string           `json:"justification,omitempty"`
+	ImpactStatement string           `json:"impact_statement,omitempty"`
+	ActionStatement string           `json:"action_statement,omitempty"`
+	Timestamp       string           `json:"timestamp,omitempty"`
+}
+
+// vulnerability a statement is about
+type VEXVulnerability struct {
+	ID      string   `json:"@id,omitempty"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// product a statement applies to, optionally narrowed to subcomponents
+type VEXProduct struct {
+	VEXComponent
+	Subcomponents []VEXComponent `json:"subcomponents,omitempty"`
+}
+
+// software component identified by purl, digest or both
+type VEXComponent struct {
+	ID string `json:"@id,omitempty"`
+	// identifier type (purl, cpe23, cpe22) to identifier
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+	// hash algorithm (sha-256, sha-512, ...) to hex value
+	Hashes map[string]string `json:"hashes,omitempty"`
+}
+
+// check required document and statement fields
+func (d *VEXDocument) Validate() error {
+	if !strings.HasPrefix(d.Context, "https://openvex.dev/ns") {
+		return fmt.Errorf("invalid OpenVEX @context %q", d.Context)
+	}
+	for i, statement := range d.Statements {
+		if statement.Vulnerability.Name == "" && statement.Vulnerability.ID == "" {
+			return fmt.Errorf("statement %d has no vulnerability name", i)
+		}
+		if !slices.Contains(vexStatuses, statement.Status) {
+			return fmt.Errorf("statement %d has invalid status %q, must be one of %v", i, statement.Status, vexStatuses)
+		}
+		if statement.Status == VEXStatusNotAffected && statement.Justification == "" && statement.ImpactStatement == "" {
+			return fmt.Errorf("not_affected statement %d requires a justification or impact statement", i)
+		}
+	}
+	return nil
+}
+
+// vex statement applied to a scan finding
+type VEXStatus struct {
+	Status          string `json:"status"`
+	Justification   string `json:"justification,omitempty"`
+	ImpactStatement string `json:"impactStatement,omitempty"`
+	ActionStatement string `json:"actionStatement,omitempty"`
+	// id of the vex document the statement came from
+	Document string `json:"document,omitempty"`
+}
+
+// reports whether the finding no longer affects the subject
+func (s *VEXStatus) Resolved() bool {
+	return s != nil && (s.Status == VEXStatusNotAffected || s.Status == VEXStatusFixed)
+}
+
+// vex documents applied to a scan
+type VEXResult struct {
+	Documents []string `json:"documents"`
+	// findings dropped as not_affected or fixed
+	Suppressed []ScanResult `json:"suppressed,omitempty"`
+}
+
+// triage decisions a vex document is generated from
+type VEXTriage struct {
+	// optional document id, derived from the statements when empty
+	ID         string               `yaml:"id" json:"id"`
+	Author     string               `yaml:"author" json:"author"`
+	Role       string               `yaml:"role" json:"role"`
+	Statements []VEXTriageStatement `yaml:"statements" json:"statements"`
+}
+
+// triage decision for one vulnerability in the subjects
+type VEXTriageStatement struct {
+	Vulnerability   string   `yaml:"vulnerability" json:"vulnerability"`
+	Aliases         []string `yaml:"aliases" json:"aliases"`
+	Status          string   `yaml:"status" json:"status"`
+	Justification   string   `yaml:"justification" json:"justification"`
+	ImpactStatement string   `yaml:"impact_statement" json:"impact_statement"`
+	ActionStatement string   `yaml:"action_statement" json:"action_statement"`
+	// purls of affected packages within the subjects, the whole subject when empty
+	Subcomponents []string `yaml:"subcomponents" json:"subcomponents"`
+}
+
+// options for creating a vex attestation
+type VEXOptions struct {
+	Type       ArtifactType
+	Subjects   []Subject
+	TriagePath string
+	// author used when the triage file names none
+	Author    string
+	Timestamp time.Time
+}
+
+// parse yaml or json triage file
+func ParseVEXTriage(data []byte) (*VEXTriage, error) {
+	var triage VEXTriage
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&triage); err != nil {
+		return nil, fmt.Errorf("invalid VEX triage file: %w", err)
+	}
+	if len(triage.Statements) == 0 {
+		return nil, fmt.Errorf("VEX triage file has no statements")
+	}
+	return &triage, nil
+}
+
+// create openvex document applying triage statements to subjects
+func NewVEXDocument(triage *VEXTriage, opts VEXOptions) (*VEXDocument, error) {
+	if len(opts.Subjects) == 0 {
+		return nil, fmt.Errorf("vex requires at least one subject")
+	}
+	author := triage.Author
+	if author == "" {
+		author = opts.Author
+	}
+	if author == "" {
+		return nil, fmt.Errorf("VEX author is required")
+	}
+	for _, subject := range opts.Subjects {
+		if subject.ShaDigest() == "" {
+			return nil, fmt.Errorf("vex subject %s requires a sha256, sha512 or sha384 digest", subject.Name)
+		}
+	}
+
+	doc := &VEXDocument{
+		Context:   OpenVEXContext,
+		ID:        triage.ID,
+		Author:    author,
+		Role:      triage.Role,
+		Timestamp: opts.Timestamp.UTC().Format(time.RFC3339),
+		Version:   1,
+		Tooling:   "autogov-helper",
+		Subjects:  opts.Subjects,
+	}
+
+	for i, entry := range triage.Statements {
+		if entry.Vulnerability == "" {
+			return nil, fmt.Errorf("triage statement %d has no vulnerability", i)
+		}
+		if entry.Justification != "" && !slices.Contains(vexJustifications, entry.Justification) {
+			return nil, fmt.Errorf("triage statement %d has invalid justification %q, must be one of %v",
+				i, entry.Justification, vexJustifications)
+		}
+		if entry.Status == VEXStatusAffected && entry.ActionStatement == "" {
+			return nil, fmt.Errorf("affected triage statement %d requires an action statement", i)
+		}
+
+		var subcomponents []VEXComponent
+		for _, purl := range entry.Subcomponents {
+			subcomponents = append(subcomponents, VEXComponent{ID: purl})
+		}
+		statement := VEXStatement{
+			Vulnerability:   VEXVulnerability{Name: entry.Vulnerability, Aliases: entry.Aliases},
+			Status:          entry.Status,
+			Justification:   entry.Justification,
+			ImpactStatement: entry.ImpactStatement,
+			ActionStatement: entry.ActionStatement,
+		}
+		for _, subject := range opts.Subjects {
+			statement.Products = append(statement.Products, VEXProduct{
+				VEXComponent:  vexSubjectComponent(subject, opts.Type),
+				Subcomponents: subcomponents,
+			})
+		}
+		doc.Statements = append(doc.Statements, statement)
+	}
+
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+
+	// content addressed id, as generated by vexctl
+	if doc.ID == "" {
+		statements, err := json.Marshal(doc.Statements)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(statements)
+		doc.ID = "https://openvex.dev/docs/public/vex-" + hex.EncodeToString(sum[:])
+	}
+
+	return doc, nil
+}
+
+// product component of a subject, images are also identified by their oci purl
+//
+// openvex has no hash algorithm for gitoids or directory digests, so only the
+// sha digests become hashes
+func vexSubjectComponent(subject Subject, artifactType ArtifactType) VEXComponent {
+	component := VEXComponent{Hashes: map[string]string{}}
+	for _, alg := range shaAlgorithms {
+		if value, ok := subject.Digest[alg]; ok {
+			component.Hashes["sha-"+strings.TrimPrefix(alg, "sha")] = value
+		}
+	}
+
+	// ghcr.io/org/app:tag -> pkg:oci/app@sha256%3Aabc?repository_url=ghcr.io/org/app
+	digest, ok := subject.Digest["sha256"]
+	if artifactType != ArtifactTypeContainerImage || !ok {
+		return component
+	}
+	repository, _, _ := strings.Cut(subject.Name, "@")
+	slash := strings.LastIndex(repository, "/")
+	if colon := strings.LastIndex(repository, ":"); colon > slash {
+		repository = repository[:colon]
+	}
+	name := repository[slash+1:]
+	component.ID = fmt.Sprintf("pkg:oci/%s@sha256%%3A%s?repository_url=%s", name, digest, repository)
+	return component
+}
+
+// generate json output
+func (d *VEXDocument) Generate() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// generate in-toto statement json output
+func (d *VEXDocument) GenerateStatement() ([]byte, error) {
+	predicate, err := d.Generate()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Subjects) == 0 {
+		return nil, fmt.Errorf("vex requires at least one subject")
+	}
+
+	return NewStatement(OpenVEXPredicateTypeURI, d.Subjects, predicate).Generate()
+}
@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+// osv-scanner json output, or an osv.dev api response listing vulns
+type OSVResult struct {
+	Results []struct {
+		Source struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		} `json:"source"`
+		Packages []struct {
+			Package         OSVPackage         `json:"package"`
+			Vulnerabilities []OSVVulnerability `json:"vulnerabilities"`
+			Groups          []struct {
+				IDs []string `json:"ids"`
+				// highest cvss base score of the group
+				MaxSeverity string `json:"max_severity"`
+			} `json:"groups"`
+		} `json:"packages"`
+	} `json:"results"`
+	// osv.dev query response
+	Vulns []OSVVulnerability `json:"vulns"`
+}
+
+// package a vulnerability was found in, ecosystem as in the osv schema (Go, npm, PyPI, Debian:12, ...)
+type OSVPackage struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+// osv schema vulnerability entry
+type OSVVulnerability struct {
+	ID       string    `json:"id"`
+	Modified time.Time `json:"modified"`
+	Aliases  []string  `json:"aliases"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	// fields of the source database, ghsa records its severity label here
+	DatabaseSpecific map[string]any `json:"database_specific"`
+}
@@ -0,0 +1,160 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ProvenancePredicateTypeURI = "https://slsa.dev/provenance/v1"
+	// github actions workflow build type
+	GitHubWorkflowBuildType = "https://actions.github.io/buildtypes/workflow/v1"
+)
+
+// predicate portion of a slsa provenance v1 attestation
+type Provenance struct {
+	BuildDefinition struct {
+		BuildType            string               `json:"buildType"`
+		ExternalParameters   ExternalParameters   `json:"externalParameters"`
+		InternalParameters   InternalParameters   `json:"internalParameters"`
+		ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Metadata struct {
+			InvocationID string `json:"invocationId"`
+			StartedOn    string `json:"startedOn,omitempty"`
+			FinishedOn   string `json:"finishedOn,omitempty"`
+		} `json:"metadata"`
+	} `json:"runDetails"`
+
+	// statement subjects, not part of predicate
+	Subjects []Subject `json:"-"`
+}
+
+// build inputs under control of the workflow author
+type ExternalParameters struct {
+	Workflow struct {
+		Ref        string `json:"ref"`
+		Repository string `json:"repository"`
+		Path       string `json:"path"`
+	} `json:"workflow"`
+	Inputs map[string]any `json:"inputs,omitempty"`
+}
+
+// build inputs set by the github platform
+type InternalParameters struct {
+	GitHub struct {
+		EventName         string `json:"event_name"`
+		RepositoryID      string `json:"repository_id"`
+		RepositoryOwnerID string `json:"repository_owner_id"`
+		RunnerEnvironment string `json:"runner_environment"`
+	} `json:"github"`
+}
+
+// in-toto resource descriptor
+type ResourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// options for creating provenance
+type ProvenanceOptions struct {
+	Subjects []Subject
+
+	// repo fields
+	ServerURL         string
+	Repository        string
+	RepositoryID      string
+	RepositoryOwnerID string
+
+	// wf fields, workflow ref is owner/repo/path@ref
+	WorkflowRef string
+	Inputs      map[string]any
+	Event       string
+	Environment string
+
+	// source fields, ref is the full git ref (refs/heads/main)
+	Ref string
+	SHA string
+
+	// run fields
+	RunID      string
+	RunAttempt string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// create provenance from github workflow options
+func NewProvenance(opts ProvenanceOptions) (*Provenance, error) {
+	workflowPath, workflowRef, found := strings.Cut(opts.WorkflowRef, "@")
+	if !found || workflowRef == "" {
+		return nil, fmt.Errorf("invalid workflow ref %q, expected owner/repo/path@ref", opts.WorkflowRef)
+	}
+	workflowPath = strings.TrimPrefix(workflowPath, opts.Repository+"/")
+	serverURL := strings.TrimSuffix(opts.ServerURL, "/")
+	repoURL := serverURL + "/" + opts.Repository
+
+	p := &Provenance{Subjects: opts.Subjects}
+
+	// set build definition
+	p.BuildDefinition.BuildType = GitHubWorkflowBuildType
+	p.BuildDefinition.ExternalParameters.Workflow.Ref = workflowRef
+	p.BuildDefinition.ExternalParameters.Workflow.Repository = repoURL
+	p.BuildDefinition.ExternalParameters.Workflow.Path = workflowPath
+	if len(opts.Inputs) > 0 {
+		p.BuildDefinition.ExternalParameters.Inputs = opts.Inputs
+	}
+	p.BuildDefinition.InternalParameters.GitHub.EventName = opts.Event
+	p.BuildDefinition.InternalParameters.GitHub.RepositoryID = opts.RepositoryID
+	p.BuildDefinition.InternalParameters.GitHub.RepositoryOwnerID = opts.RepositoryOwnerID
+	p.BuildDefinition.InternalParameters.GitHub.RunnerEnvironment = opts.Environment
+
+	// source repo at the built commit
+	ref := opts.Ref
+	if ref == "" {
+		ref = workflowRef
+	}
+	p.BuildDefinition.ResolvedDependencies = []ResourceDescriptor{{
+		URI:    fmt.Sprintf("git+%s@%s", repoURL, ref),
+		Digest: map[string]string{"gitCommit": opts.SHA},
+	}}
+
+	// set run details
+	attempt := opts.RunAttempt
+	if attempt == "" {
+		attempt = "1"
+	}
+	p.RunDetails.Builder.ID = serverURL + "/" + opts.WorkflowRef
+	p.RunDetails.Metadata.InvocationID = fmt.Sprintf("%s/actions/runs/%s/attempts/%s", repoURL, opts.RunID, attempt)
+	if !opts.StartedAt.IsZero() {
+		p.RunDetails.Metadata.StartedOn = opts.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if !opts.FinishedAt.IsZero() {
+		p.RunDetails.Metadata.FinishedOn = opts.FinishedAt.UTC().Format(time.RFC3339)
+	}
+
+	return p, nil
+}
+
+// generate json output
+func (p *Provenance) Generate() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// generate in-toto statement json output
+func (p *Provenance) GenerateStatement() ([]byte, error) {
+	predicate, err := p.Generate()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Subjects) == 0 {
+		return nil, fmt.Errorf("provenance requires at least one subject")
+	}
+
+	return NewStatement(ProvenancePredicateTypeURI, p.Subjects, predicate).Generate()
+}
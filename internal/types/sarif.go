@@ -0,0 +1,65 @@
+package types
+
+import "encoding/json"
+
+// sarif 2.1.0 log
+type SARIFLog struct {
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// one analysis run of a tool
+type SARIFRun struct {
+	Tool struct {
+		Driver struct {
+			Name            string      `json:"name"`
+			Version         string      `json:"version"`
+			SemanticVersion string      `json:"semanticVersion"`
+			InformationURI  string      `json:"informationUri"`
+			Rules           []SARIFRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Invocations []struct {
+		StartTimeUTC string `json:"startTimeUtc"`
+		EndTimeUTC   string `json:"endTimeUtc"`
+	} `json:"invocations"`
+	Results []SARIFResult `json:"results"`
+}
+
+// rule metadata reported by the tool
+type SARIFRule struct {
+	ID                   string `json:"id"`
+	Name                 string `json:"name"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+	Properties SARIFProperties `json:"properties"`
+}
+
+// rule or result property bag
+type SARIFProperties struct {
+	// cvss like 0.0-10.0 score used by github code scanning, a string or number
+	SecuritySeverity json.RawMessage `json:"security-severity"`
+	Tags             []string        `json:"tags"`
+}
+
+// finding reported by the tool
+type SARIFResult struct {
+	RuleID    string `json:"ruleId"`
+	RuleIndex *int   `json:"ruleIndex"`
+	Level     string `json:"level"`
+	Message   struct {
+		Text string `json:"text"`
+	} `json:"message"`
+	Locations []struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine int `json:"startLine"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	} `json:"locations"`
+	Properties SARIFProperties `json:"properties"`
+}
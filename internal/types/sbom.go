@@ -0,0 +1,158 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+const (
+	SPDXPredicateTypeURI      = "https://spdx.dev/Document"
+	CycloneDXPredicateTypeURI = "https://cyclonedx.org/bom"
+)
+
+// sbom document format
+type SBOMFormat string
+
+const (
+	SBOMFormatSPDX      SBOMFormat = "spdx"
+	SBOMFormatCycloneDX SBOMFormat = "cyclonedx"
+)
+
+// supported document versions
+var (
+	spdxVersions      = []string{"SPDX-2.3"}
+	cycloneDXVersions = []string{"1.5", "1.6"}
+)
+
+// sbom document used as the predicate
+type SBOM struct {
+	Format SBOMFormat
+	// spdxVersion or specVersion of the document
+	Version       string
+	PredicateType string
+	Document      json.RawMessage
+
+	// statement subjects, not part of predicate
+	Subjects []Subject
+}
+
+// options for creating an sbom attestation
+type SBOMOptions struct {
+	Subjects     []Subject
+	DocumentPath string
+}
+
+// spdx 2.3 fields required for a well-formed document
+type spdxDocument struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      *struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+}
+
+// cyclonedx fields required for a well-formed document
+type cycloneDXDocument struct {
+	BOMFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+}
+
+// detect format of spdx or cyclonedx json document and check it is well-formed
+func ParseSBOM(data []byte) (*SBOM, error) {
+	var probe struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("invalid SBOM JSON: %w", err)
+	}
+
+	var sbom *SBOM
+	var err error
+	switch {
+	case probe.SPDXVersion != "":
+		sbom, err = parseSPDX(data)
+	case probe.BOMFormat != "":
+		sbom, err = parseCycloneDX(data)
+	default:
+		return nil, fmt.Errorf("unrecognized SBOM, expected an SPDX or CycloneDX JSON document")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var document bytes.Buffer
+	if err := json.Indent(&document, data, "", "  "); err != nil {
+		return nil, fmt.Errorf("invalid SBOM JSON: %w", err)
+	}
+	sbom.Document = document.Bytes()
+	return sbom, nil
+}
+
+// check spdx document
+func parseSPDX(data []byte) (*SBOM, error) {
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid SPDX document: %w", err)
+	}
+	if !slices.Contains(spdxVersions, doc.SPDXVersion) {
+		return nil, fmt.Errorf("unsupported SPDX version %q, supported: %v", doc.SPDXVersion, spdxVersions)
+	}
+
+	var missing []string
+	if doc.DataLicense == "" {
+		missing = append(missing, "dataLicense")
+	}
+	if doc.SPDXID != "SPDXRef-DOCUMENT" {
+		missing = append(missing, "SPDXID (SPDXRef-DOCUMENT)")
+	}
+	if doc.Name == "" {
+		missing = append(missing, "name")
+	}
+	if doc.DocumentNamespace == "" {
+		missing = append(missing, "documentNamespace")
+	}
+	if doc.CreationInfo == nil || doc.CreationInfo.Created == "" || len(doc.CreationInfo.Creators) == 0 {
+		missing = append(missing, "creationInfo")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("invalid SPDX document, missing or invalid fields: %v", missing)
+	}
+
+	return &SBOM{Format: SBOMFormatSPDX, Version: doc.SPDXVersion, PredicateType: SPDXPredicateTypeURI}, nil
+}
+
+// check cyclonedx document
+func parseCycloneDX(data []byte) (*SBOM, error) {
+	var doc cycloneDXDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX document: %w", err)
+	}
+	if doc.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("invalid CycloneDX document, bomFormat is %q", doc.BOMFormat)
+	}
+	if !slices.Contains(cycloneDXVersions, doc.SpecVersion) {
+		return nil, fmt.Errorf("unsupported CycloneDX spec version %q, supported: %v", doc.SpecVersion, cycloneDXVersions)
+	}
+
+	return &SBOM{Format: SBOMFormatCycloneDX, Version: doc.SpecVersion, PredicateType: CycloneDXPredicateTypeURI}, nil
+}
+
+// generate json output
+func (s *SBOM) Generate() ([]byte, error) {
+	return s.Document, nil
+}
+
+// generate in-toto statement json output
+func (s *SBOM) GenerateStatement() ([]byte, error) {
+	if len(s.Subjects) == 0 {
+		return nil, fmt.Errorf("sbom requires at least one subject")
+	}
+	return NewStatement(s.PredicateType, s.Subjects, s.Document).Generate()
+}
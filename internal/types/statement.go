@@ -0,0 +1,153 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const StatementTypeURI = "https://in-toto.io/Statement/v1"
+
+// in-toto statement subject
+type Subject struct {
+	Name        string            `json:"name"`
+	Digest      map[string]string `json:"digest"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// in-toto statement v1
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// create subject from name and prefixed digests (e.g. sha256:abc,sha512:def)
+func NewSubject(name, digest string) (Subject, error) {
+	if name == "" {
+		return Subject{}, fmt.Errorf("subject name is required")
+	}
+	if digest == "" {
+		return Subject{}, fmt.Errorf("subject digest is required for %s", name)
+	}
+
+	digests := map[string]string{}
+	for _, d := range strings.Split(digest, ",") {
+		alg, value, err := ParseDigest(strings.TrimSpace(d))
+		if err != nil {
+			return Subject{}, fmt.Errorf("invalid digest for %s: %w", name, err)
+		}
+		if existing, ok := digests[alg]; ok && existing != value {
+			return Subject{}, fmt.Errorf("conflicting %s digests for %s", alg, name)
+		}
+		digests[alg] = value
+	}
+
+	return Subject{
+		Name:   name,
+		Digest: digests,
+	}, nil
+}
+
+// digest algorithms of content hashes, in order of preference
+var shaAlgorithms = []string{"sha256", "sha512", "sha384"}
+
+// subject digest keys of gitoids and path aware directory digests
+var identifierAlgorithms = []string{"gitoid:sha1", "gitoid:sha256", "dirHash", "merkleSha256"}
+
+// split prefixed digest into algorithm and value
+//
+// gitoids keep their own prefix, gitoid:blob:sha256:abc is keyed gitoid:sha256
+func ParseDigest(digest string) (string, string, error) {
+	if rest, ok := strings.CutPrefix(digest, "gitoid:blob:"); ok {
+		hashAlg, value, found := strings.Cut(rest, ":")
+		if !found || value == "" || !slices.Contains(identifierAlgorithms, "gitoid:"+hashAlg) {
+			return "", "", fmt.Errorf("invalid gitoid %q", digest)
+		}
+		return "gitoid:" + hashAlg, digest, nil
+	}
+
+	alg, value, found := strings.Cut(digest, ":")
+	if !found {
+		// bare digests are assumed to be sha256
+		alg, value = "sha256", digest
+	}
+	if alg == "" || value == "" {
+		return "", "", fmt.Errorf("invalid digest %q", digest)
+	}
+	if !slices.Contains(shaAlgorithms, alg) && !slices.Contains(identifierAlgorithms, alg) {
+		return "", "", fmt.Errorf("unsupported digest algorithm %q in %q, must be one of %v", alg, digest,
+			append(slices.Clone(shaAlgorithms), identifierAlgorithms...))
+	}
+	return alg, value, nil
+}
+
+// prefixed form of digest value, the inverse of ParseDigest
+func FormatDigest(alg, value string) string {
+	if strings.HasPrefix(alg, "gitoid:") {
+		return value
+	}
+	return alg + ":" + value
+}
+
+// reports whether subject has matching digest
+//
+// hex digests compare case insensitively, dirhash h1 values are base64 and
+// compare exactly
+func (s Subject) HasDigest(digest string) bool {
+	alg, value, err := ParseDigest(digest)
+	if err != nil {
+		return false
+	}
+	existing, ok := s.Digest[alg]
+	if !ok {
+		return false
+	}
+	if alg == "dirHash" {
+		return existing == value
+	}
+	return strings.EqualFold(existing, value)
+}
+
+// prefixed sha256, sha512 or sha384 digest of subject in that order, empty when it has none
+func (s Subject) ShaDigest() string {
+	for _, alg := range shaAlgorithms {
+		if value, ok := s.Digest[alg]; ok {
+			return FormatDigest(alg, value)
+		}
+	}
+	return ""
+}
+
+// prefixed digest of subject, preferring sha digests over gitoids and directory digests
+func (s Subject) PrimaryDigest() string {
+	if digest := s.ShaDigest(); digest != "" {
+		return digest
+	}
+	for _, alg := range identifierAlgorithms {
+		if value, ok := s.Digest[alg]; ok {
+			return FormatDigest(alg, value)
+		}
+	}
+	return ""
+}
+
+// create new statement wrapping predicate
+func NewStatement(predicateType string, subjects []Subject, predicate []byte) *Statement {
+	return &Statement{
+		Type:          StatementTypeURI,
+		Subject:       subjects,
+		PredicateType: predicateType,
+		Predicate:     predicate,
+	}
+}
+
+// generate json output
+func (s *Statement) Generate() ([]byte, error) {
+	if len(s.Subject) == 0 {
+		return nil, fmt.Errorf("statement requires at least one subject")
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const StaticAnalysisPredicateTypeURI = "https://github.com/liatrio/autogov-helper/static-analysis/v0.1"
+
+// predicate portion of a static analysis attestation
+type StaticAnalysis struct {
+	Scanner struct {
+		Name    string    `json:"name"`
+		URI     string    `json:"uri"`
+		Version string    `json:"version"`
+		Result  []Finding `json:"result"`
+	} `json:"scanner"`
+	Metadata struct {
+		ScanStartedOn  string `json:"scanStartedOn"`
+		ScanFinishedOn string `json:"scanFinishedOn"`
+	} `json:"metadata"`
+
+	// statement subjects, not part of predicate
+	Subjects []Subject `json:"-"`
+}
+
+// static analysis finding, a scan result with the code it was found in
+type Finding struct {
+	ScanResult
+	Message   string     `json:"message,omitempty"`
+	Locations []Location `json:"locations,omitempty"`
+}
+
+// source file region of a finding
+type Location struct {
+	URI       string `json:"uri"`
+	StartLine int    `json:"startLine,omitempty"`
+}
+
+// options for creating a static analysis attestation
+type StaticAnalysisOptions struct {
+	Subjects    []Subject
+	ResultsPath string
+	StartedAt   time.Time
+	FinishedAt  time.Time
+}
+
+// creates new static analysis instance
+func NewStaticAnalysis(opts StaticAnalysisOptions) *StaticAnalysis {
+	s := &StaticAnalysis{Subjects: opts.Subjects}
+
+	// initialize empty result array
+	s.Scanner.Result = make([]Finding, 0)
+
+	s.Metadata.ScanStartedOn = opts.StartedAt.UTC().Format(time.RFC3339Nano)
+	s.Metadata.ScanFinishedOn = opts.FinishedAt.UTC().Format(time.RFC3339Nano)
+
+	return s
+}
+
+// generate json output
+func (s *StaticAnalysis) Generate() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// generate in-toto statement json output
+func (s *StaticAnalysis) GenerateStatement() ([]byte, error) {
+	predicate, err := s.Generate()
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Subjects) == 0 {
+		return nil, fmt.Errorf("static analysis requires at least one subject")
+	}
+
+	return NewStatement(StaticAnalysisPredicateTypeURI, s.Subjects, predicate).Generate()
+}
@@ -0,0 +1,52 @@
+package types
+
+import "time"
+
+// trivy json report (schema version 2)
+type TrivyResult struct {
+	SchemaVersion int       `json:"SchemaVersion"`
+	CreatedAt     time.Time `json:"CreatedAt"`
+	ArtifactName  string    `json:"ArtifactName"`
+	ArtifactType  string    `json:"ArtifactType"`
+	// scanner info, recorded by recent trivy releases
+	Trivy struct {
+		Version string `json:"Version"`
+	} `json:"Trivy"`
+	Results []struct {
+		Target          string               `json:"Target"`
+		Class           string               `json:"Class"`
+		Type            string               `json:"Type"`
+		Vulnerabilities []TrivyVulnerability `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// vulnerability found by trivy
+type TrivyVulnerability struct {
+	VulnerabilityID string `json:"VulnerabilityID"`
+	PkgName         string `json:"PkgName"`
+	PkgIdentifier   struct {
+		PURL string `json:"PURL"`
+	} `json:"PkgIdentifier"`
+	InstalledVersion string `json:"InstalledVersion"`
+	FixedVersion     string `json:"FixedVersion"`
+	Status           string `json:"Status"`
+	Severity         string `json:"Severity"`
+	SeveritySource   string `json:"SeveritySource"`
+	DataSource       *struct {
+		ID   string `json:"ID"`
+		Name string `json:"Name"`
+		URL  string `json:"URL"`
+	} `json:"DataSource"`
+	// cvss scores by vendor (nvd, ghsa, redhat, ...)
+	CVSS map[string]TrivyCVSS `json:"CVSS"`
+}
+
+// cvss scores and vectors from one vendor
+type TrivyCVSS struct {
+	V2Vector  string  `json:"V2Vector"`
+	V3Vector  string  `json:"V3Vector"`
+	V40Vector string  `json:"V40Vector"`
+	V2Score   float64 `json:"V2Score"`
+	V3Score   float64 `json:"V3Score"`
+	V40Score  float64 `json:"V40Score"`
+}
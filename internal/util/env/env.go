@@ -14,12 +14,15 @@ const (
 	EnvGitHubRepositoryOwner = "GITHUB_REPOSITORY_OWNER"
 	EnvGitHubOwnerID         = "GITHUB_REPOSITORY_OWNER_ID"
 	EnvGitHubServerURL       = "GITHUB_SERVER_URL"
+	EnvGitHubAPIURL          = "GITHUB_API_URL"
 	EnvGitHubSHA             = "GITHUB_SHA"
 	EnvGitHubRefName         = "GITHUB_REF_NAME"
 	EnvGitHubEventName       = "GITHUB_EVENT_NAME"
 	EnvGitHubActor           = "GITHUB_ACTOR"
 	EnvGitHubRunID           = "GITHUB_RUN_ID"
 	EnvGitHubRunNumber       = "GITHUB_RUN_NUMBER"
+	EnvGitHubRunAttempt      = "GITHUB_RUN_ATTEMPT"
+	EnvGitHubRef             = "GITHUB_REF"
 	EnvGitHubWorkflowRef     = "GITHUB_WORKFLOW_REF"
 	EnvGitHubJobStatus       = "JOB_STATUS"
 	EnvGitHubEventPath       = "GITHUB_EVENT_PATH"
@@ -31,3 +31,14 @@ func TestNewError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to do something")
 }
+
+func TestExitCode(t *testing.T) {
+	originalErr := errors.New("original error")
+
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(originalErr))
+	assert.Equal(t, 3, ExitCode(WithExitCode(3, originalErr)))
+	assert.Equal(t, 3, ExitCode(WrapError("verify", WithExitCode(3, originalErr))))
+	assert.ErrorIs(t, WithExitCode(3, originalErr), originalErr)
+	assert.NoError(t, WithExitCode(3, nil))
+}
@@ -0,0 +1,99 @@
+package fileutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// digest algorithms by hex digest length
+var checksumAlgorithms = map[int]string{
+	64:  "sha256",
+	96:  "sha384",
+	128: "sha512",
+}
+
+// bsd style line: SHA256 (name) = digest
+var bsdChecksumLine = regexp.MustCompile(`^([A-Za-z0-9-]+) \((.+)\) = ([0-9a-fA-F]+)$`)
+
+// named digest from a checksums file
+type Checksum struct {
+	Name      string
+	Algorithm string
+	Digest    string
+}
+
+// reads sha256sum style checksums file, plain or base64 encoded
+func ReadChecksumsFile(path string) ([]Checksum, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checksums file: %w", err)
+	}
+	return ParseChecksums(data)
+}
+
+// parses checksums, falling back to base64 decoding the whole content
+func ParseChecksums(data []byte) ([]Checksum, error) {
+	checksums, err := parseChecksumLines(data)
+	if err == nil {
+		return checksums, nil
+	}
+
+	// base64 encoded checksums as used by attestation workflows
+	decoded, decodeErr := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if decodeErr != nil {
+		return nil, err
+	}
+	return parseChecksumLines(decoded)
+}
+
+// parses gnu (digest  name) or bsd (ALG (name) = digest) lines
+func parseChecksumLines(data []byte) ([]Checksum, error) {
+	var checksums []Checksum
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var checksum Checksum
+		if m := bsdChecksumLine.FindStringSubmatch(line); m != nil {
+			checksum = Checksum{Name: m[2], Algorithm: strings.ToLower(m[1]), Digest: m[3]}
+		} else {
+			digest, name, found := strings.Cut(line, " ")
+			if !found {
+				return nil, fmt.Errorf("invalid checksum on line %d", lineNum)
+			}
+			// binary mode marks names with '*', text mode with ' '
+			name = strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")
+			checksum = Checksum{Name: name, Digest: digest}
+		}
+
+		if _, err := hex.DecodeString(checksum.Digest); err != nil || checksum.Name == "" {
+			return nil, fmt.Errorf("invalid checksum on line %d", lineNum)
+		}
+		if alg, ok := checksumAlgorithms[len(checksum.Digest)]; ok && checksum.Algorithm == "" {
+			checksum.Algorithm = alg
+		}
+		if checksum.Algorithm == "" {
+			return nil, fmt.Errorf("unsupported digest length %d on line %d", len(checksum.Digest), lineNum)
+		}
+		checksum.Digest = strings.ToLower(checksum.Digest)
+
+		checksums = append(checksums, checksum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksums: %w", err)
+	}
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("no checksums found")
+	}
+
+	return checksums, nil
+}
@@ -0,0 +1,71 @@
+package fileutil
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testChecksums = `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  app_linux_amd64.tar.gz
+2C26B46B68FFC68FF99B453C1D30413413422D706483BFA0F98A5E886266E7AE *app_windows_amd64.zip
+
+# comments and blank lines are ignored
+SHA512 (app_darwin_arm64.tar.gz) = cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e
+`
+
+func TestParseChecksums(t *testing.T) {
+	t.Run("plain checksums", func(t *testing.T) {
+		checksums, err := ParseChecksums([]byte(testChecksums))
+		require.NoError(t, err)
+		require.Len(t, checksums, 3)
+
+		assert.Equal(t, Checksum{
+			Name:      "app_linux_amd64.tar.gz",
+			Algorithm: "sha256",
+			Digest:    "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		}, checksums[0])
+		assert.Equal(t, "app_windows_amd64.zip", checksums[1].Name)
+		assert.Equal(t, "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", checksums[1].Digest)
+		assert.Equal(t, "app_darwin_arm64.tar.gz", checksums[2].Name)
+		assert.Equal(t, "sha512", checksums[2].Algorithm)
+	})
+
+	t.Run("base64 checksums", func(t *testing.T) {
+		encoded := base64.StdEncoding.EncodeToString([]byte(testChecksums))
+		checksums, err := ParseChecksums([]byte(encoded + "\n"))
+		require.NoError(t, err)
+		assert.Len(t, checksums, 3)
+	})
+
+	t.Run("invalid digest", func(t *testing.T) {
+		_, err := ParseChecksums([]byte("not-hex  app.tar.gz\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported digest length", func(t *testing.T) {
+		_, err := ParseChecksums([]byte("abcd  app.tar.gz\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported digest length")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := ParseChecksums([]byte("\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestReadChecksumsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checksums.txt")
+	require.NoError(t, os.WriteFile(path, []byte(testChecksums), 0600))
+
+	checksums, err := ReadChecksumsFile(path)
+	require.NoError(t, err)
+	assert.Len(t, checksums, 3)
+
+	_, err = ReadChecksumsFile(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,149 @@
+package fileutil
+
+import (
+	"crypto/sha1" //nolint:gosec // gitoid:sha1 is an identifier, not a security control
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// content digest algorithm, named after its subject digest key
+type HashAlgorithm string
+
+const (
+	HashSHA256 HashAlgorithm = "sha256"
+	HashSHA384 HashAlgorithm = "sha384"
+	HashSHA512 HashAlgorithm = "sha512"
+	// omnibor gitoid of a git blob, value is gitoid:blob:<hash>:<hex>
+	HashGitOIDSHA1   HashAlgorithm = "gitoid:sha1"
+	HashGitOIDSHA256 HashAlgorithm = "gitoid:sha256"
+)
+
+// reports whether algorithm is a gitoid
+func (a HashAlgorithm) IsGitOID() bool {
+	return strings.HasPrefix(string(a), "gitoid:")
+}
+
+// new hash for algorithm
+func (a HashAlgorithm) newHash() hash.Hash {
+	switch a {
+	case HashSHA384:
+		return sha512.New384()
+	case HashSHA512:
+		return sha512.New()
+	case HashGitOIDSHA1:
+		return sha1.New() //nolint:gosec // gitoid:sha1 is an identifier
+	default:
+		return sha256.New()
+	}
+}
+
+// parse digest algorithm names, gitoid is short for gitoid:sha256
+//
+// defaults to sha256 when no names are given
+func ParseHashAlgorithms(names []string) ([]HashAlgorithm, error) {
+	var algs []HashAlgorithm
+	seen := map[HashAlgorithm]bool{}
+	for _, name := range names {
+		alg := HashAlgorithm(strings.ToLower(strings.TrimSpace(name)))
+		switch alg {
+		case "":
+			continue
+		case "gitoid":
+			alg = HashGitOIDSHA256
+		case HashSHA256, HashSHA384, HashSHA512, HashGitOIDSHA1, HashGitOIDSHA256:
+		default:
+			return nil, fmt.Errorf("invalid digest algorithm %q, must be 'sha256', 'sha384', 'sha512', 'gitoid:sha1' or 'gitoid:sha256'", name)
+		}
+		if !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	if len(algs) == 0 {
+		algs = []HashAlgorithm{HashSHA256}
+	}
+	return algs, nil
+}
+
+// computes several digests over one stream of content
+type multiHash struct {
+	algs   []HashAlgorithm
+	hashes []hash.Hash
+	io.Writer
+}
+
+// new multi hash, size is the content length needed by gitoid headers
+func newMultiHash(algs []HashAlgorithm, size int64) *multiHash {
+	m := &multiHash{algs: algs}
+	writers := make([]io.Writer, 0, len(algs))
+	for _, alg := range algs {
+		h := alg.newHash()
+		if alg.IsGitOID() {
+			fmt.Fprintf(h, "blob %d\x00", size)
+		}
+		m.hashes = append(m.hashes, h)
+		writers = append(writers, h)
+	}
+	m.Writer = io.MultiWriter(writers...)
+	return m
+}
+
+// digest values keyed by algorithm name
+func (m *multiHash) digests() map[string]string {
+	digests := make(map[string]string, len(m.algs))
+	for i, alg := range m.algs {
+		value := hex.EncodeToString(m.hashes[i].Sum(nil))
+		if alg.IsGitOID() {
+			value = "gitoid:blob:" + strings.TrimPrefix(string(alg), "gitoid:") + ":" + value
+		}
+		digests[string(alg)] = value
+	}
+	return digests
+}
+
+// digests of a single file computed in one pass
+func hashFileDigests(path string, algs []HashAlgorithm) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	m := newMultiHash(algs, info.Size())
+	n, err := io.Copy(m, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate digest: %w", err)
+	}
+	if n != info.Size() {
+		return nil, fmt.Errorf("file %s changed while calculating digest", path)
+	}
+	return m.digests(), nil
+}
+
+// digests of file contents concatenated in order
+func hashFilesDigests(files []string, algs []HashAlgorithm) (map[string]string, error) {
+	for _, alg := range algs {
+		if alg.IsGitOID() {
+			return nil, fmt.Errorf("%s digests are only supported for single files", alg)
+		}
+	}
+
+	m := newMultiHash(algs, 0)
+	for _, file := range files {
+		if err := hashFile(m, file); err != nil {
+			return nil, err
+		}
+	}
+	return m.digests(), nil
+}
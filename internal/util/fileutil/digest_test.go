@@ -0,0 +1,69 @@
+package fileutil
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHashAlgorithms(t *testing.T) {
+	algs, err := ParseHashAlgorithms(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []HashAlgorithm{HashSHA256}, algs)
+
+	algs, err = ParseHashAlgorithms([]string{"sha512", "SHA256", "gitoid", "sha512"})
+	require.NoError(t, err)
+	assert.Equal(t, []HashAlgorithm{HashSHA512, HashSHA256, HashGitOIDSHA256}, algs)
+
+	_, err = ParseHashAlgorithms([]string{"md5"})
+	assert.Error(t, err)
+}
+
+func TestMultiAlgorithmDigests(t *testing.T) {
+	content := []byte("hello\n")
+
+	t.Run("file digests in one pass", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hello.txt")
+		require.NoError(t, os.WriteFile(path, content, 0600))
+
+		result, err := CalculateDigestWithOptions(path, DigestOptions{
+			Algorithms: []HashAlgorithm{HashSHA256, HashSHA384, HashSHA512, HashGitOIDSHA1, HashGitOIDSHA256},
+		})
+		require.NoError(t, err)
+
+		sum256 := sha256.Sum256(content)
+		sum384 := sha512.Sum384(content)
+		sum512 := sha512.Sum512(content)
+		gitoid256 := sha256.Sum256(append([]byte("blob 6\x00"), content...))
+		assert.Equal(t, map[string]string{
+			"sha256": hex.EncodeToString(sum256[:]),
+			"sha384": hex.EncodeToString(sum384[:]),
+			"sha512": hex.EncodeToString(sum512[:]),
+			// matches git hash-object
+			"gitoid:sha1":   "gitoid:blob:sha1:ce013625030ba8dba906f756967f9e9ca394464a",
+			"gitoid:sha256": "gitoid:blob:sha256:" + hex.EncodeToString(gitoid256[:]),
+		}, result.Digests)
+	})
+
+	t.Run("directory concat digests", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, map[string]string{"a.txt": "hel", "b.txt": "lo\n"})
+
+		result, err := CalculateDigestWithOptions(dir, DigestOptions{Algorithms: []HashAlgorithm{HashSHA256, HashSHA512}})
+		require.NoError(t, err)
+
+		sum256 := sha256.Sum256(content)
+		sum512 := sha512.Sum512(content)
+		assert.Equal(t, hex.EncodeToString(sum256[:]), result.Digests["sha256"])
+		assert.Equal(t, hex.EncodeToString(sum512[:]), result.Digests["sha512"])
+
+		_, err = CalculateDigestWithOptions(dir, DigestOptions{Algorithms: []HashAlgorithm{HashGitOIDSHA256}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,347 @@
+package fileutil
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// directory digest algorithm
+type DirAlgorithm string
+
+const (
+	// sha256 over concatenated file contents, ignores paths
+	DirAlgorithmConcat DirAlgorithm = "sha256"
+	// go dirhash h1 over relative paths and file digests
+	DirAlgorithmDirHash DirAlgorithm = "dirhash"
+	// merkle tree over relative paths and file digests
+	DirAlgorithmMerkle DirAlgorithm = "merkle"
+)
+
+// subject digest keys for path aware algorithms
+const (
+	DigestKeyDirHash = "dirHash"
+	DigestKeyMerkle  = "merkleSha256"
+)
+
+// how symlinks are handled in path aware digests
+type SymlinkPolicy string
+
+const (
+	// hash the content of the link target
+	SymlinkFollow SymlinkPolicy = "follow"
+	// hash the link target path instead of its content
+	SymlinkRecord SymlinkPolicy = "record"
+	// leave symlinks out of the digest
+	SymlinkSkip SymlinkPolicy = "skip"
+)
+
+// options for file, directory and pattern digests
+type DigestOptions struct {
+	DirAlgorithm DirAlgorithm
+	Symlinks     SymlinkPolicy
+	Filter       FileFilter
+	// content digests for files and concatenated directories, defaults to sha256
+	Algorithms []HashAlgorithm
+}
+
+// content digest algorithms, defaulting to sha256
+func (o DigestOptions) hashAlgorithms() []HashAlgorithm {
+	if len(o.Algorithms) == 0 {
+		return []HashAlgorithm{HashSHA256}
+	}
+	return o.Algorithms
+}
+
+// per-file entry of a path aware digest
+type ManifestEntry struct {
+	Path   string `json:"path"`
+	Digest string `json:"digest"`
+}
+
+// digest of a file, directory or pattern
+type DigestResult struct {
+	// digest values keyed by algorithm name
+	Digests map[string]string
+	// per-file digests for path aware digests
+	Manifest []ManifestEntry
+}
+
+// parse directory digest algorithm name
+func ParseDirAlgorithm(name string) (DirAlgorithm, error) {
+	switch DirAlgorithm(name) {
+	case "", DirAlgorithmConcat:
+		return DirAlgorithmConcat, nil
+	case DirAlgorithmDirHash, DirAlgorithmMerkle:
+		return DirAlgorithm(name), nil
+	default:
+		return "", fmt.Errorf("invalid directory digest algorithm %q, must be 'sha256', 'dirhash' or 'merkle'", name)
+	}
+}
+
+// parse symlink policy name
+func ParseSymlinkPolicy(name string) (SymlinkPolicy, error) {
+	switch SymlinkPolicy(name) {
+	case "", SymlinkFollow:
+		return SymlinkFollow, nil
+	case SymlinkRecord, SymlinkSkip:
+		return SymlinkPolicy(name), nil
+	default:
+		return "", fmt.Errorf("invalid symlink policy %q, must be 'follow', 'record' or 'skip'", name)
+	}
+}
+
+// calculates digest of file/dir/glob pattern with options
+func CalculateDigestWithOptions(path string, opts DigestOptions) (*DigestResult, error) {
+	if err := opts.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	// concatenated digests read link targets like plain files
+	symlinks := opts.Symlinks
+	if opts.DirAlgorithm == "" || opts.DirAlgorithm == DirAlgorithmConcat {
+		symlinks = SymlinkFollow
+	}
+
+	var base string
+	var entries []fileEntry
+	if IsGlob(path) {
+		files, err := ExpandGlobFiltered(path, opts.Filter)
+		if err != nil {
+			return nil, err
+		}
+		// paths are relative to the static part of the pattern
+		base = globBase(path)
+		for _, file := range files {
+			entries = append(entries, fileEntry{path: file})
+		}
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat path: %w", err)
+		}
+		// single files always use their content digest
+		if !info.IsDir() {
+			digests, err := hashFileDigests(path, opts.hashAlgorithms())
+			if err != nil {
+				return nil, err
+			}
+			return &DigestResult{Digests: digests}, nil
+		}
+
+		base = path
+		entries, err = walkFiles(path, symlinks, opts.Filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.DirAlgorithm == "" || opts.DirAlgorithm == DirAlgorithmConcat {
+		// same ordering as listFiles
+		files := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			files = append(files, entry.path)
+		}
+		sort.Strings(files)
+		digests, err := hashFilesDigests(files, opts.hashAlgorithms())
+		if err != nil {
+			return nil, err
+		}
+		return &DigestResult{Digests: digests}, nil
+	}
+
+	manifest, err := buildManifest(base, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DigestResult{Digests: map[string]string{}, Manifest: manifest}
+	switch opts.DirAlgorithm {
+	case DirAlgorithmDirHash:
+		result.Digests[DigestKeyDirHash] = dirHash1(manifest)
+	case DirAlgorithmMerkle:
+		root, err := merkleRoot(manifest)
+		if err != nil {
+			return nil, err
+		}
+		result.Digests[DigestKeyMerkle] = root
+	default:
+		return nil, fmt.Errorf("unsupported directory digest algorithm %q", opts.DirAlgorithm)
+	}
+
+	return result, nil
+}
+
+// file found while walking a directory
+type fileEntry struct {
+	path string
+	// symlink target recorded instead of content
+	linkTarget string
+}
+
+// walks dir applying symlink policy and filter
+func walkFiles(dir string, policy SymlinkPolicy, filter FileFilter) ([]fileEntry, error) {
+	var entries []fileEntry
+	var rules []ignoreRule
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && (filter.excludes(rel) || isIgnored(rules, rel, true)) {
+				return filepath.SkipDir
+			}
+			if filter.IgnoreFiles {
+				base := rel
+				if base == "." {
+					base = ""
+				}
+				dirRules, err := loadIgnoreRules(path, base)
+				if err != nil {
+					return err
+				}
+				rules = append(rules, dirRules...)
+			}
+			return nil
+		}
+		if !filter.Allows(rel) || isIgnored(rules, rel, false) {
+			return nil
+		}
+		if filter.IgnoreFiles && d.Name() == AttestIgnoreFile {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink == 0 {
+			entries = append(entries, fileEntry{path: path})
+			return nil
+		}
+
+		switch policy {
+		case SymlinkSkip:
+			return nil
+		case SymlinkRecord:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, fileEntry{path: path, linkTarget: filepath.ToSlash(target)})
+		default:
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return fmt.Errorf("symlinked directory %s cannot be followed, use record or skip policy", path)
+			}
+			entries = append(entries, fileEntry{path: path})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+	return entries, nil
+}
+
+// hashes entries into manifest sorted by slash separated relative path
+func buildManifest(base string, entries []fileEntry) ([]ManifestEntry, error) {
+	manifest := make([]ManifestEntry, 0, len(entries))
+	for _, entry := range entries {
+		rel, err := filepath.Rel(base, entry.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relative path for %s: %w", entry.path, err)
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.Contains(rel, "\n") {
+			return nil, fmt.Errorf("file name %q contains a newline", rel)
+		}
+
+		h := sha256.New()
+		if entry.linkTarget != "" {
+			h.Write([]byte(entry.linkTarget))
+		} else if err := hashFile(h, entry.path); err != nil {
+			return nil, err
+		}
+
+		manifest = append(manifest, ManifestEntry{Path: rel, Digest: hex.EncodeToString(h.Sum(nil))})
+	}
+
+	sort.Slice(manifest, func(i, j int) bool { return manifest[i].Path < manifest[j].Path })
+	return manifest, nil
+}
+
+// copy file content into writer
+func hashFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(w, f); err != nil {
+		return fmt.Errorf("failed to calculate digest for %s: %w", path, err)
+	}
+	return nil
+}
+
+// go dirhash h1 summary of manifest
+func dirHash1(manifest []ManifestEntry) string {
+	h := sha256.New()
+	for _, entry := range manifest {
+		fmt.Fprintf(h, "%s  %s\n", entry.Digest, entry.Path)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// merkle root over manifest, leaves bind path and file digest
+func merkleRoot(manifest []ManifestEntry) (string, error) {
+	if len(manifest) == 0 {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:]), nil
+	}
+
+	level := make([][]byte, 0, len(manifest))
+	for _, entry := range manifest {
+		digest, err := hex.DecodeString(entry.Digest)
+		if err != nil {
+			return "", fmt.Errorf("invalid digest for %s: %w", entry.Path, err)
+		}
+		h := sha256.New()
+		h.Write([]byte{0x00})
+		h.Write([]byte(entry.Path))
+		h.Write([]byte{0x00})
+		h.Write(digest)
+		level = append(level, h.Sum(nil))
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			// odd node is promoted unchanged
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			h := sha256.New()
+			h.Write([]byte{0x01})
+			h.Write(level[i])
+			h.Write(level[i+1])
+			next = append(next, h.Sum(nil))
+		}
+		level = next
+	}
+
+	return hex.EncodeToString(level[0]), nil
+}
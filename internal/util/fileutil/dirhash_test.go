@@ -0,0 +1,145 @@
+package fileutil
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// create files under dir
+func writeTestTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+}
+
+func TestCalculateDigestWithOptions(t *testing.T) {
+	t.Run("dirhash matches h1 format", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, map[string]string{"b.txt": "bravo", "a/c.txt": "charlie"})
+
+		result, err := CalculateDigestWithOptions(dir, DigestOptions{DirAlgorithm: DirAlgorithmDirHash})
+		require.NoError(t, err)
+
+		// h1 summary of "<sha256>  <path>\n" lines sorted by path
+		h := sha256.New()
+		for _, f := range []struct{ path, content string }{{"a/c.txt", "charlie"}, {"b.txt", "bravo"}} {
+			sum := sha256.Sum256([]byte(f.content))
+			fmt.Fprintf(h, "%x  %s\n", sum, f.path)
+		}
+		expected := "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil))
+		assert.Equal(t, map[string]string{DigestKeyDirHash: expected}, result.Digests)
+
+		require.Len(t, result.Manifest, 2)
+		assert.Equal(t, "a/c.txt", result.Manifest[0].Path)
+	})
+
+	for _, alg := range []DirAlgorithm{DirAlgorithmDirHash, DirAlgorithmMerkle} {
+		t.Run(string(alg)+" is path aware", func(t *testing.T) {
+			original := t.TempDir()
+			writeTestTree(t, original, map[string]string{"one.txt": "abc", "two.txt": "def"})
+			renamed := t.TempDir()
+			writeTestTree(t, renamed, map[string]string{"uno.txt": "abc", "two.txt": "def"})
+			shifted := t.TempDir()
+			writeTestTree(t, shifted, map[string]string{"one.txt": "ab", "two.txt": "cdef"})
+
+			digests := map[string]map[string]string{}
+			for name, dir := range map[string]string{"original": original, "renamed": renamed, "shifted": shifted} {
+				result, err := CalculateDigestWithOptions(dir, DigestOptions{DirAlgorithm: alg})
+				require.NoError(t, err)
+				digests[name] = result.Digests
+			}
+			assert.NotEqual(t, digests["original"], digests["renamed"])
+			assert.NotEqual(t, digests["original"], digests["shifted"])
+
+			// legacy digest cannot tell the trees apart
+			legacyOriginal, err := CalculateDigest(original)
+			require.NoError(t, err)
+			legacyShifted, err := CalculateDigest(shifted)
+			require.NoError(t, err)
+			assert.Equal(t, legacyOriginal, legacyShifted)
+		})
+	}
+
+	t.Run("merkle is reproducible", func(t *testing.T) {
+		files := map[string]string{"a.txt": "a", "b/c.txt": "c", "b/d.txt": "d"}
+		first, second := t.TempDir(), t.TempDir()
+		writeTestTree(t, first, files)
+		writeTestTree(t, second, files)
+
+		r1, err := CalculateDigestWithOptions(first, DigestOptions{DirAlgorithm: DirAlgorithmMerkle})
+		require.NoError(t, err)
+		r2, err := CalculateDigestWithOptions(second, DigestOptions{DirAlgorithm: DirAlgorithmMerkle})
+		require.NoError(t, err)
+		assert.Equal(t, r1.Digests, r2.Digests)
+		assert.Len(t, r1.Digests[DigestKeyMerkle], 64)
+		assert.Equal(t, r1.Manifest, r2.Manifest)
+	})
+
+	t.Run("single file uses sha256", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, map[string]string{"app": "binary"})
+
+		result, err := CalculateDigestWithOptions(filepath.Join(dir, "app"), DigestOptions{DirAlgorithm: DirAlgorithmDirHash})
+		require.NoError(t, err)
+		sum := sha256.Sum256([]byte("binary"))
+		assert.Equal(t, map[string]string{"sha256": hex.EncodeToString(sum[:])}, result.Digests)
+	})
+
+	t.Run("glob paths are relative to pattern base", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, map[string]string{"src/main.go": "main", "src/pkg/util.go": "util"})
+
+		result, err := CalculateDigestWithOptions(filepath.Join(dir, "src", "**", "*.go"),
+			DigestOptions{DirAlgorithm: DirAlgorithmDirHash})
+		require.NoError(t, err)
+		require.Len(t, result.Manifest, 2)
+		assert.Equal(t, "main.go", result.Manifest[0].Path)
+		assert.Equal(t, "pkg/util.go", result.Manifest[1].Path)
+	})
+}
+
+func TestSymlinkPolicy(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTree(t, dir, map[string]string{"target.txt": "target"})
+	require.NoError(t, os.Symlink("target.txt", filepath.Join(dir, "link.txt")))
+
+	manifest := func(policy SymlinkPolicy) []ManifestEntry {
+		result, err := CalculateDigestWithOptions(dir, DigestOptions{DirAlgorithm: DirAlgorithmDirHash, Symlinks: policy})
+		require.NoError(t, err)
+		return result.Manifest
+	}
+
+	followed := manifest(SymlinkFollow)
+	require.Len(t, followed, 2)
+	assert.Equal(t, followed[0].Digest, followed[1].Digest)
+
+	recorded := manifest(SymlinkRecord)
+	require.Len(t, recorded, 2)
+	sum := sha256.Sum256([]byte("target.txt"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), recorded[0].Digest)
+
+	assert.Len(t, manifest(SymlinkSkip), 1)
+}
+
+func TestParseDirAlgorithm(t *testing.T) {
+	alg, err := ParseDirAlgorithm("")
+	require.NoError(t, err)
+	assert.Equal(t, DirAlgorithmConcat, alg)
+
+	_, err = ParseDirAlgorithm("md5")
+	assert.Error(t, err)
+
+	_, err = ParseSymlinkPolicy("ignore")
+	assert.Error(t, err)
+}
@@ -8,10 +8,22 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
 )
 
-// calculates sha256 digest of file/dir
+// calculates sha256 digest of file/dir/glob pattern
 func CalculateDigest(path string) (string, error) {
+	// combined digest of all files matching pattern
+	if IsGlob(path) {
+		files, err := ExpandGlob(path)
+		if err != nil {
+			return "", err
+		}
+		return digestFiles(files)
+	}
+
 	info, err := os.Stat(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to stat path: %w", err)
@@ -25,19 +37,7 @@ func CalculateDigest(path string) (string, error) {
 		}
 
 		// get combined digest of all files
-		h := sha256.New()
-		for _, file := range files {
-			f, err := os.Open(file)
-			if err != nil {
-				return "", fmt.Errorf("failed to open file %s: %w", file, err)
-			}
-			if _, err := io.Copy(h, f); err != nil {
-				f.Close()
-				return "", fmt.Errorf("failed to calculate digest for %s: %w", file, err)
-			}
-			f.Close()
-		}
-		return fmt.Sprintf("sha256:%s", hex.EncodeToString(h.Sum(nil))), nil
+		return digestFiles(files)
 	}
 
 	// handle single file
@@ -55,6 +55,46 @@ func CalculateDigest(path string) (string, error) {
 	return fmt.Sprintf("sha256:%s", hex.EncodeToString(h.Sum(nil))), nil
 }
 
+// reports whether path contains glob meta characters and is not an existing
+// file or directory, so literal names like app[1].bin are not expanded
+func IsGlob(path string) bool {
+	if !strings.ContainsAny(path, "*?[{") {
+		return false
+	}
+	_, err := os.Lstat(path)
+	return err != nil
+}
+
+// expands doublestar glob pattern to sorted list of matching files
+func ExpandGlob(pattern string) ([]string, error) {
+	files, err := doublestar.FilepathGlob(pattern, doublestar.WithFilesOnly(), doublestar.WithFailOnIOErrors())
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand pattern %s: %w", pattern, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files match pattern %s", pattern)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// combined sha256 digest of file contents in order
+func digestFiles(files []string) (string, error) {
+	h := sha256.New()
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to open file %s: %w", file, err)
+		}
+		if _, err := io.Copy(h, f); err != nil {
+			f.Close()
+			return "", fmt.Errorf("failed to calculate digest for %s: %w", file, err)
+		}
+		f.Close()
+	}
+	return fmt.Sprintf("sha256:%s", hex.EncodeToString(h.Sum(nil))), nil
+}
+
 // lists all files in dir
 func listFiles(dir string) ([]string, error) {
 	var files []string
@@ -107,3 +107,70 @@ func Test_listFiles(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestExpandGlob(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{
+		"main.go",
+		filepath.Join("pkg", "util.go"),
+		filepath.Join("pkg", "nested", "deep.go"),
+		filepath.Join("pkg", "README.md"),
+		filepath.Join("build", "app.jar"),
+	}
+	for _, f := range files {
+		path := filepath.Join(tmpDir, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(f), 0600))
+	}
+
+	t.Run("doublestar pattern", func(t *testing.T) {
+		matches, err := ExpandGlob(filepath.Join(tmpDir, "**", "*.go"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(tmpDir, "main.go"),
+			filepath.Join(tmpDir, "pkg", "nested", "deep.go"),
+			filepath.Join(tmpDir, "pkg", "util.go"),
+		}, matches)
+	})
+
+	t.Run("files only", func(t *testing.T) {
+		matches, err := ExpandGlob(filepath.Join(tmpDir, "*"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(tmpDir, "main.go")}, matches)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		_, err := ExpandGlob(filepath.Join(tmpDir, "**", "*.war"))
+		assert.Error(t, err)
+	})
+
+	t.Run("combined digest", func(t *testing.T) {
+		pattern := filepath.Join(tmpDir, "**", "*.go")
+		digest, err := CalculateDigest(pattern)
+		require.NoError(t, err)
+
+		matches, err := ExpandGlob(pattern)
+		require.NoError(t, err)
+		expected, err := digestFiles(matches)
+		require.NoError(t, err)
+		assert.Equal(t, expected, digest)
+	})
+
+	t.Run("is glob", func(t *testing.T) {
+		assert.True(t, IsGlob("**/*.go"))
+		assert.True(t, IsGlob("app-{linux,darwin}"))
+		assert.False(t, IsGlob("dist/app.tar.gz"))
+	})
+
+	t.Run("bracketed filename", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app[1].bin")
+		require.NoError(t, os.WriteFile(path, []byte("app"), 0600))
+		assert.False(t, IsGlob(path))
+		assert.True(t, IsGlob(filepath.Join(dir, "app[2].bin")))
+
+		digest, err := CalculateDigest(path)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:a172cedcae47474b615c54d510a5d84a8dea3032e958587430b413538be3f333", digest)
+	})
+}
@@ -0,0 +1,256 @@
+package fileutil
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignore file honored only by this tool, never part of the digest
+const AttestIgnoreFile = ".attestignore"
+
+// ignore files read when ignore file support is enabled
+var ignoreFileNames = []string{".gitignore", AttestIgnoreFile}
+
+// include and exclude rules for directory and pattern digests
+type FileFilter struct {
+	// doublestar patterns, when set files must match at least one
+	Include []string
+	// doublestar patterns for files and directories to leave out
+	Exclude []string
+	// honor .gitignore and .attestignore files found in directories
+	IgnoreFiles bool
+}
+
+// reports whether filter keeps every file
+func (f FileFilter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0 && !f.IgnoreFiles
+}
+
+// checks include and exclude patterns are valid
+func (f FileFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid filter pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// reports whether file at slash separated relative path is kept
+func (f FileFilter) Allows(rel string) bool {
+	if f.excludes(rel) {
+		return false
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if matchPattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// reports whether path matches an exclude pattern
+func (f FileFilter) excludes(rel string) bool {
+	for _, pattern := range f.Exclude {
+		if matchPattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// match relative path, patterns without separator match base name at any depth
+func matchPattern(pattern, rel string) bool {
+	if doublestar.MatchUnvalidated(pattern, rel) {
+		return true
+	}
+	return !strings.Contains(pattern, "/") && doublestar.MatchUnvalidated(pattern, path.Base(rel))
+}
+
+// expands glob pattern and keeps matches allowed by filter
+func ExpandGlobFiltered(pattern string, filter FileFilter) ([]string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	files, err := ExpandGlob(pattern)
+	if err != nil || filter.IsZero() {
+		return files, err
+	}
+
+	base := globBase(pattern)
+	ignore := &globIgnore{base: base, dirRules: map[string][]ignoreRule{}}
+	var kept []string
+	for _, file := range files {
+		rel, err := filepath.Rel(base, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relative path for %s: %w", file, err)
+		}
+		rel = filepath.ToSlash(rel)
+		if !filter.Allows(rel) {
+			continue
+		}
+		if filter.IgnoreFiles {
+			ignored, err := ignore.ignores(rel)
+			if err != nil {
+				return nil, err
+			}
+			if ignored {
+				continue
+			}
+		}
+		kept = append(kept, file)
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("all files matching pattern %s are filtered out", pattern)
+	}
+	return kept, nil
+}
+
+// ignore file rules for glob matches below the static part of a pattern
+type globIgnore struct {
+	base string
+	// rules read from each slash separated directory relative to base
+	dirRules map[string][]ignoreRule
+}
+
+// reports whether relative path, or a directory it is in, is ignored
+func (g *globIgnore) ignores(rel string) (bool, error) {
+	if path.Base(rel) == AttestIgnoreFile {
+		return true, nil
+	}
+
+	var rules []ignoreRule
+	dir := ""
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		dirRules, ok := g.dirRules[dir]
+		if !ok {
+			var err error
+			dirRules, err = loadIgnoreRules(filepath.Join(g.base, filepath.FromSlash(dir)), dir)
+			if err != nil {
+				return false, err
+			}
+			g.dirRules[dir] = dirRules
+		}
+		rules = append(rules, dirRules...)
+
+		dir = path.Join(dir, part)
+		if isIgnored(rules, dir, i < len(parts)-1) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// static directory part of glob pattern
+func globBase(pattern string) string {
+	base, _ := doublestar.SplitPattern(filepath.ToSlash(pattern))
+	return filepath.FromSlash(base)
+}
+
+// single gitignore style rule
+type ignoreRule struct {
+	// slash separated directory of the ignore file relative to walk root
+	base    string
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// reads gitignore style rules from file in directory base
+func readIgnoreFile(file, base string) ([]ignoreRule, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			// escaped leading # or !
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		// patterns with a separator are anchored to the ignore file directory
+		if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		rule.pattern = strings.TrimPrefix(line, "/")
+		if rule.pattern == "" || !doublestar.ValidatePattern(rule.pattern) {
+			return nil, fmt.Errorf("invalid pattern %q in %s", scanner.Text(), file)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+	return rules, nil
+}
+
+// reports whether rule matches relative path
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, r.base+"/")
+	}
+	return doublestar.MatchUnvalidated(r.pattern, rel)
+}
+
+// reports whether path is ignored, the last matching rule wins
+func isIgnored(rules []ignoreRule, rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.match(rel, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// reads ignore files present in dir
+func loadIgnoreRules(dir, base string) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	for _, name := range ignoreFileNames {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to stat ignore file: %w", err)
+		}
+		fileRules, err := readIgnoreFile(file, base)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+	return rules, nil
+}
@@ -0,0 +1,123 @@
+package fileutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// relative paths in manifest of dir digested with filter
+func filteredPaths(t *testing.T, dir string, filter FileFilter) []string {
+	t.Helper()
+	result, err := CalculateDigestWithOptions(dir, DigestOptions{DirAlgorithm: DirAlgorithmDirHash, Filter: filter})
+	require.NoError(t, err)
+	var paths []string
+	for _, entry := range result.Manifest {
+		paths = append(paths, entry.Path)
+	}
+	return paths
+}
+
+func TestFileFilter(t *testing.T) {
+	files := map[string]string{
+		"app":             "binary",
+		".DS_Store":       "junk",
+		"lib/util.so":     "lib",
+		"lib/.DS_Store":   "junk",
+		"logs/build.log":  "log",
+		"docs/readme.txt": "docs",
+	}
+
+	t.Run("exclude matches base names at any depth", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, files)
+
+		paths := filteredPaths(t, dir, FileFilter{Exclude: []string{".DS_Store", "logs"}})
+		assert.Equal(t, []string{"app", "docs/readme.txt", "lib/util.so"}, paths)
+	})
+
+	t.Run("include keeps only matching files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, files)
+
+		paths := filteredPaths(t, dir, FileFilter{Include: []string{"lib/**", "app"}, Exclude: []string{".DS_Store"}})
+		assert.Equal(t, []string{"app", "lib/util.so"}, paths)
+	})
+
+	t.Run("ignore files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, files)
+		writeTestTree(t, dir, map[string]string{
+			".gitignore":       "# junk\n.DS_Store\n*.log\n",
+			".attestignore":    "/docs/\n",
+			"lib/.gitignore":   "*.so\n!util.so\n",
+			"lib/docs/api.txt": "api",
+		})
+
+		paths := filteredPaths(t, dir, FileFilter{IgnoreFiles: true})
+		assert.Equal(t, []string{".gitignore", "app", "lib/.gitignore", "lib/docs/api.txt", "lib/util.so"}, paths)
+	})
+
+	t.Run("ignore files are not read by default", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, map[string]string{"app": "binary", ".gitignore": "app\n"})
+
+		assert.Equal(t, []string{".gitignore", "app"}, filteredPaths(t, dir, FileFilter{}))
+	})
+
+	t.Run("concat digest honors filter", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, files)
+		shipped := t.TempDir()
+		writeTestTree(t, shipped, map[string]string{"app": "binary", "lib/util.so": "lib"})
+
+		result, err := CalculateDigestWithOptions(dir, DigestOptions{Filter: FileFilter{Include: []string{"app", "*.so"}}})
+		require.NoError(t, err)
+		expected, err := CalculateDigest(shipped)
+		require.NoError(t, err)
+		assert.Equal(t, expected, "sha256:"+result.Digests["sha256"])
+	})
+
+	t.Run("filtered glob", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, files)
+
+		matches, err := ExpandGlobFiltered(filepath.Join(dir, "**"), FileFilter{Exclude: []string{".DS_Store", "*.log"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "app"),
+			filepath.Join(dir, "docs", "readme.txt"),
+			filepath.Join(dir, "lib", "util.so"),
+		}, matches)
+
+		_, err = ExpandGlobFiltered(filepath.Join(dir, "logs", "*"), FileFilter{Exclude: []string{"*.log"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("glob honors ignore files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTestTree(t, dir, files)
+		writeTestTree(t, dir, map[string]string{
+			".gitignore":     ".DS_Store\n*.log\n",
+			".attestignore":  "/docs/\n",
+			"lib/.gitignore": "*.so\n!util.so\n",
+			"lib/extra.so":   "extra",
+		})
+
+		matches, err := ExpandGlobFiltered(filepath.Join(dir, "**"), FileFilter{IgnoreFiles: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, ".gitignore"),
+			filepath.Join(dir, "app"),
+			filepath.Join(dir, "lib", ".gitignore"),
+			filepath.Join(dir, "lib", "util.so"),
+		}, matches)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := CalculateDigestWithOptions(t.TempDir(), DigestOptions{Filter: FileFilter{Exclude: []string{"[a"}}})
+		assert.Error(t, err)
+	})
+}
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"autogov-helper/internal/attestation"
+	"autogov-helper/internal/oci"
 	"autogov-helper/internal/types"
+	"autogov-helper/internal/util/errors"
 	"autogov-helper/internal/util/fileutil"
 
 	"github.com/spf13/cobra"
@@ -17,7 +19,7 @@ func main() {
 	cmd := newRootCommand()
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(errors.ExitCode(err))
 	}
 }
 
@@ -31,20 +33,266 @@ func newRootCommand() *cobra.Command {
 	cmd.AddCommand(
 		newMetadataCommand(),
 		newDepscanCommand(),
+		newSignCommand(),
+		newVerifyCommand(),
+		newPushCommand(),
+		newUploadCommand(),
+		newFetchCommand(),
+		newProvenanceCommand(),
+		newSBOMCommand(),
+		newStaticAnalysisCommand(),
+		newVEXCommand(),
 	)
 
 	return cmd
 }
 
+// output flags shared by attestation commands
+type outputFlags struct {
+	file     string
+	format   string
+	signKey  string
+	signCert string
+}
+
+// register output flags
+func (f *outputFlags) register(cmd *cobra.Command, outputUsage string) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.file, "output", "", outputUsage)
+	flags.StringVar(&f.format, "output-format", "predicate", "Output format (predicate, statement, dsse or bundle)")
+	flags.StringVar(&f.signKey, "sign-key", "", "PEM private key (ECDSA P-256, Ed25519 or RSA) to sign a DSSE envelope")
+	flags.StringVar(&f.signCert, "sign-cert", "", "PEM certificate for the signing key, embedded in bundle output")
+}
+
+// build output options from flags
+func (f *outputFlags) options(cmd *cobra.Command) (attestation.OutputOptions, error) {
+	format, err := attestation.ParseOutputFormat(f.format)
+	if err != nil {
+		return attestation.OutputOptions{}, err
+	}
+
+	// signing flags imply a signed format unless format set explicitly
+	if f.signKey != "" && !cmd.Flags().Changed("output-format") {
+		format = attestation.OutputFormatDSSE
+		if f.signCert != "" {
+			format = attestation.OutputFormatBundle
+		}
+	}
+
+	switch {
+	case format.IsSigned() && f.signKey == "":
+		return attestation.OutputOptions{}, fmt.Errorf("--sign-key is required for %s output", format)
+	case !format.IsSigned() && f.signKey != "":
+		return attestation.OutputOptions{}, fmt.Errorf("--sign-key cannot be used with %s output", format)
+	case format != attestation.OutputFormatBundle && f.signCert != "":
+		return attestation.OutputOptions{}, fmt.Errorf("--sign-cert can only be used with bundle output")
+	}
+
+	return attestation.OutputOptions{
+		File:     f.file,
+		Format:   format,
+		SignKey:  f.signKey,
+		SignCert: f.signCert,
+	}, nil
+}
+
+// blob subject flags shared by attestation commands
+type blobFlags struct {
+	checksumsPath string
+	globMode      string
+	dirAlgorithm  string
+	symlinks      string
+	manifestPath  string
+	filter        filterFlags
+	algorithms    []string
+}
+
+// register blob subject flags
+func (f *blobFlags) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.checksumsPath, "subjects-checksums", "",
+		"sha256sum style checksums file (plain or base64) listing blob subjects")
+	flags.StringVar(&f.globMode, "subject-glob-mode", "each",
+		"How glob patterns in --subject-path become subjects (each or combined)")
+	flags.StringVar(&f.manifestPath, "dir-manifest", "",
+		"Write per-file manifest of dirhash or merkle digests to this file")
+	f.registerDigest(cmd)
+}
+
+// register flags controlling how blob digests are calculated
+func (f *blobFlags) registerDigest(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.dirAlgorithm, "dir-digest-algorithm", "sha256",
+		"Digest algorithm for directories and combined globs (sha256, dirhash or merkle)")
+	flags.StringVar(&f.symlinks, "symlinks", "follow",
+		"Symlink policy for dirhash and merkle digests (follow, record or skip)")
+	flags.StringSliceVar(&f.algorithms, "digest-algorithms", []string{"sha256"},
+		"Comma separated digest algorithms for blob subjects (sha256, sha384, sha512, gitoid:sha1, gitoid:sha256)")
+	f.filter.register(cmd)
+}
+
+// build blob subject options from flags
+func (f *blobFlags) options(paths, digests []string) (attestation.BlobSubjectOptions, error) {
+	globMode, err := attestation.ParseGlobMode(f.globMode)
+	if err != nil {
+		return attestation.BlobSubjectOptions{}, err
+	}
+	digest, err := f.digestOptions()
+	if err != nil {
+		return attestation.BlobSubjectOptions{}, err
+	}
+
+	return attestation.BlobSubjectOptions{
+		Paths:         paths,
+		Digests:       digests,
+		ChecksumsPath: f.checksumsPath,
+		GlobMode:      globMode,
+		Digest:        digest,
+		ManifestPath:  f.manifestPath,
+	}, nil
+}
+
+// build digest options from flags
+func (f *blobFlags) digestOptions() (fileutil.DigestOptions, error) {
+	dirAlgorithm, err := fileutil.ParseDirAlgorithm(f.dirAlgorithm)
+	if err != nil {
+		return fileutil.DigestOptions{}, err
+	}
+	symlinks, err := fileutil.ParseSymlinkPolicy(f.symlinks)
+	if err != nil {
+		return fileutil.DigestOptions{}, err
+	}
+	algorithms, err := fileutil.ParseHashAlgorithms(f.algorithms)
+	if err != nil {
+		return fileutil.DigestOptions{}, err
+	}
+
+	return fileutil.DigestOptions{
+		DirAlgorithm: dirAlgorithm,
+		Symlinks:     symlinks,
+		Filter:       f.filter.filter(),
+		Algorithms:   algorithms,
+	}, nil
+}
+
+// file filter flags for directory and pattern digests
+type filterFlags struct {
+	include     []string
+	exclude     []string
+	ignoreFiles bool
+}
+
+// register file filter flags
+func (f *filterFlags) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringArrayVar(&f.include, "include", nil,
+		"Only digest files in directories and patterns matching this glob (repeatable)")
+	flags.StringArrayVar(&f.exclude, "exclude", nil,
+		"Leave files and directories matching this glob out of digests (repeatable)")
+	flags.BoolVar(&f.ignoreFiles, "ignore-files", false,
+		"Honor .gitignore and .attestignore files in subject directories")
+}
+
+// build file filter from flags
+func (f *filterFlags) filter() fileutil.FileFilter {
+	return fileutil.FileFilter{
+		Include:     f.include,
+		Exclude:     f.exclude,
+		IgnoreFiles: f.ignoreFiles,
+	}
+}
+
+// subject flags shared by the attestation commands
+type subjectFlags struct {
+	names     []string
+	paths     []string
+	digests   []string
+	platforms bool
+	blob      blobFlags
+}
+
+// register subject flags, nameUsage describes a subject for the command
+func (f *subjectFlags) register(cmd *cobra.Command, nameUsage string) {
+	flags := cmd.Flags()
+	flags.StringArrayVar(&f.paths, "subject-path", nil,
+		"Path or glob pattern of a subject file or directory, or for images an OCI layout or image tarball, "+
+			"repeatable (required for blob type); legacy docker save tarballs get the digest crane would push, "+
+			"which can differ from the one docker push produces")
+	flags.StringArrayVar(&f.names, "subject-name", nil,
+		"Name of a subject "+nameUsage+", repeatable (required for image type without --subject-path)")
+	flags.StringArrayVar(&f.digests, "subject-digest", nil,
+		"Prefixed digest of the subject (sha256:, sha512:, ... comma separated for several), "+
+			"repeatable and paired with --subject-name (required for image type, auto-calculated for blobs)")
+	flags.BoolVar(&f.platforms, "platform-subjects", false,
+		"Add a subject for each platform manifest of a multi-arch image index read from --subject-path")
+	f.blob.register(cmd)
+}
+
+// resolve image or blob subjects, images are returned when read from local paths
+func (f *subjectFlags) resolve(artifactType string) ([]types.Subject, []*oci.Image, error) {
+	switch artifactType {
+	case "image":
+		if f.blob.checksumsPath != "" {
+			return nil, nil, fmt.Errorf("--subjects-checksums is only supported for blob type")
+		}
+		return f.resolveImages()
+	case "blob":
+		if len(f.paths) == 0 && f.blob.checksumsPath == "" {
+			return nil, nil, fmt.Errorf("--subject-path or --subjects-checksums is required for blob type")
+		}
+		// calc digests for blobs if not provided
+		blobOpts, err := f.blob.options(f.paths, f.digests)
+		if err != nil {
+			return nil, nil, err
+		}
+		subjects, err := attestation.ResolveBlobSubjects(blobOpts)
+		return subjects, nil, err
+	default:
+		return nil, nil, fmt.Errorf("invalid type %q, must be 'image' or 'blob'", artifactType)
+	}
+}
+
+// resolve image subjects from names and digests or from local image paths
+func (f *subjectFlags) resolveImages() ([]types.Subject, []*oci.Image, error) {
+	// digests of local oci layouts and tarballs are calculated
+	if len(f.paths) > 0 {
+		if len(f.digests) > 0 {
+			return nil, nil, fmt.Errorf("--subject-digest cannot be combined with --subject-path for image type")
+		}
+		return attestation.ResolveLocalImageSubjects(f.paths, f.names, f.platforms)
+	}
+	if f.platforms {
+		return nil, nil, fmt.Errorf("--platform-subjects requires --subject-path pointing to a local OCI layout")
+	}
+
+	if len(f.names) == 0 {
+		return nil, nil, fmt.Errorf("--subject-name or --subject-path is required for image type")
+	}
+	if len(f.digests) == 0 && !strings.Contains(f.names[0], "@") {
+		return nil, nil, fmt.Errorf("--subject-digest is required for image type")
+	}
+	subjects, err := attestation.ResolveImageSubjects(f.names, f.digests)
+	if err != nil {
+		return nil, nil, err
+	}
+	return subjects, nil, nil
+}
+
 func newMetadataCommand() *cobra.Command {
 	var opts attestation.MetadataOptions
-	var outputFile string
+	var output outputFlags
 	var artifactType string
+	var subject subjectFlags
 
 	cmd := &cobra.Command{
 		Use:   "metadata",
 		Short: "Generate metadata attestation",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := output.options(cmd)
+			if err != nil {
+				return err
+			}
+
 			// set artifact type
 			switch artifactType {
 			case "image":
@@ -143,51 +391,42 @@ func newMetadataCommand() *cobra.Command {
 				"attestations": "write",
 				"contents":     "read",
 			}
-			if opts.Type == types.ArtifactTypeContainerImage {
-				opts.Permissions["packages"] = "write"
-				// add sha256 to fullname if missing
-				if !strings.Contains(opts.FullName, "@sha256:") {
-					opts.FullName = fmt.Sprintf("%s@%s", opts.FullName, opts.Digest)
-				}
-			} else {
-				opts.Permissions["packages"] = "none"
+
+			subjects, images, err := subject.resolve(artifactType)
+			if err != nil {
+				return err
 			}
+			opts.Subjects = subjects
 
 			switch opts.Type {
 			case types.ArtifactTypeContainerImage:
-				if opts.FullName == "" {
-					return fmt.Errorf("--subject-name is required for image type")
-				}
-				if opts.Digest == "" {
-					return fmt.Errorf("--subject-digest is required for image type")
+				opts.Permissions["packages"] = "write"
+
+				// predicate describes the first image
+				opts.Digest = subjects[0].PrimaryDigest()
+				opts.FullName = fmt.Sprintf("%s@%s", subjects[0].Name, opts.Digest)
+				if len(images) > 0 && images[0].Tag != "" {
+					opts.FullName = fmt.Sprintf("%s:%s@%s", subjects[0].Name, images[0].Tag, opts.Digest)
 				}
 				// get registry from hostname in subject-name
 				if parts := strings.Split(opts.FullName, "/"); len(parts) > 2 && strings.Contains(parts[0], ".") {
 					opts.Registry = parts[0]
 				}
 			case types.ArtifactTypeBlob:
-				if opts.SubjectPath == "" {
-					return fmt.Errorf("--subject-path is required for blob type")
-				}
-				// calc digest for blob if not provided
-				if opts.Digest == "" {
-					digest, err := fileutil.CalculateDigest(opts.SubjectPath)
-					if err != nil {
-						return fmt.Errorf("failed to calculate digest: %w", err)
-					}
-					opts.Digest = digest
+				opts.Permissions["packages"] = "none"
+				opts.SubjectPath = strings.Join(attestation.SubjectNames(subjects), ",")
+				if len(subjects) == 1 {
+					opts.Digest = subjects[0].PrimaryDigest()
 				}
 			}
 
-			return attestation.GenerateMetadata(opts, outputFile)
+			return attestation.GenerateMetadata(opts, out)
 		},
 	}
 
 	flags := cmd.Flags()
-	flags.StringVar(&opts.SubjectPath, "subject-path", "", "Path to the subject file (required for blob type)")
-	flags.StringVar(&opts.FullName, "subject-name", "", "Name of the subject being attested (required for image type)")
-	flags.StringVar(&opts.Digest, "subject-digest", "", "SHA256 digest of the subject (required for image type)")
-	flags.StringVar(&outputFile, "output", "", "Output file")
+	subject.register(cmd, "being attested")
+	output.register(cmd, "Output file")
 	flags.StringVar(&artifactType, "type", "image", "Type of build (image or blob)")
 
 	return cmd
@@ -195,52 +434,520 @@ func newMetadataCommand() *cobra.Command {
 
 func newDepscanCommand() *cobra.Command {
 	var opts attestation.DepscanOptions
-	var outputFile string
+	var output outputFlags
+	var gate gateFlags
+	var scanStartedAt, scanFinishedAt, dbLastUpdate string
 	var artifactType string
+	var subject subjectFlags
+	var legacyDigests []string
 
 	cmd := &cobra.Command{
 		Use:   "depscan",
 		Short: "Generate dependency scan attestation",
+		Long: `Generate a dependency scan attestation from Grype, Trivy, OSV-Scanner or SARIF results.
+
+With --fail-on or a --max-<severity> budget the findings are checked after the scan. The
+gate decision is recorded in the predicate metadata, the attestation is written, and the
+command then exits with code 5 when the budget is exceeded.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// validate and set type
-			switch artifactType {
-			case "image":
+			out, err := output.options(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts.Gate, err = gate.gate()
+			if err != nil {
+				return err
+			}
+
+			// report times are used unless overridden
+			if opts.StartedAt, err = parseTimeFlag("--scan-started-at", scanStartedAt); err != nil {
+				return err
+			}
+			if opts.FinishedAt, err = parseTimeFlag("--scan-finished-at", scanFinishedAt); err != nil {
+				return err
+			}
+			if opts.DBLastUpdate, err = parseTimeFlag("--db-last-update", dbLastUpdate); err != nil {
+				return err
+			}
+
+			subject.digests = append(subject.digests, legacyDigests...)
+			opts.Subjects, _, err = subject.resolve(artifactType)
+			if err != nil {
+				return err
+			}
+			opts.Digest = opts.Subjects[0].PrimaryDigest()
+			if artifactType == "image" {
 				opts.Type = types.ArtifactTypeContainerImage
-				if opts.SubjectName == "" {
-					return fmt.Errorf("--subject-name is required for image type")
-				}
-				if opts.Digest == "" {
-					return fmt.Errorf("--digest is required for image type")
-				}
-			case "blob":
+				opts.SubjectName = opts.Subjects[0].Name
+			} else {
 				opts.Type = types.ArtifactTypeBlob
-				if opts.SubjectPath == "" {
-					return fmt.Errorf("--subject-path is required for blob type")
-				}
-				// Calculate digest for blob if not provided
-				if opts.Digest == "" {
-					digest, err := fileutil.CalculateDigest(opts.SubjectPath)
-					if err != nil {
-						return fmt.Errorf("failed to calculate digest: %w", err)
-					}
-					opts.Digest = digest
-				}
-			default:
-				return fmt.Errorf("invalid type %q, must be 'image' or 'blob'", artifactType)
+				opts.SubjectPath = opts.Subjects[0].Name
 			}
 
-			return attestation.GenerateDepscan(opts, outputFile)
+			return attestation.GenerateDepscan(opts, out)
 		},
 	}
 
 	flags := cmd.Flags()
-	flags.StringVar(&opts.ResultsPath, "results-path", "", "Path to Grype results JSON file")
-	flags.StringVar(&opts.SubjectName, "subject-name", "", "Name of the subject being scanned (required for image type)")
-	flags.StringVar(&opts.SubjectPath, "subject-path", "", "Path to the subject file (required for blob type)")
-	flags.StringVar(&opts.Digest, "digest", "", "Digest of the subject being scanned (required for container images, auto-calculated for blobs)")
-	flags.StringVar(&outputFile, "output", "", "Output file path (defaults to stdout)")
+	flags.StringVar(&opts.ResultsPath, "results-path", "", "Path to Grype, Trivy, OSV-Scanner JSON or SARIF results file")
+	flags.StringVar(&opts.Format, "format", "auto", "Format of the results file (auto, grype, trivy, osv or sarif)")
+	subject.register(cmd, "being scanned")
+	flags.StringArrayVar(&legacyDigests, "digest", nil, "Digest of the subject being scanned")
+	cobra.CheckErr(flags.MarkDeprecated("digest", "use --subject-digest instead"))
+	output.register(cmd, "Output file path (defaults to stdout)")
 	flags.StringVar(&artifactType, "type", "image", "Type of artifact (image or blob)")
+	flags.StringVar(&scanStartedAt, "scan-started-at", "",
+		"RFC 3339 time the scan started (defaults to the time recorded in the results)")
+	flags.StringVar(&scanFinishedAt, "scan-finished-at", "",
+		"RFC 3339 time the scan finished (defaults to the time recorded in the results)")
+	flags.StringVar(&dbLastUpdate, "db-last-update", "",
+		"RFC 3339 time the vulnerability database was built (required for Trivy results, "+
+			"which do not record it; defaults to the time recorded in other results)")
+	flags.StringArrayVar(&opts.VEXPaths, "vex", nil,
+		"Path to an OpenVEX document whose statements annotate matching findings, repeatable")
+	flags.BoolVar(&opts.SuppressVEX, "vex-suppress", false,
+		"Drop findings VEX marks not_affected or fixed, listing them under metadata.vex.suppressed")
+	gate.register(cmd)
 	cobra.CheckErr(cmd.MarkFlagRequired("results-path"))
 
 	return cmd
 }
+
+// parse optional rfc 3339 time flag, zero when unset
+func parseTimeFlag(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q, expected an RFC 3339 time", flag, value)
+	}
+	return t, nil
+}
+
+// vulnerability gate flags for depscan
+type gateFlags struct {
+	failOn      string
+	maxFindings map[string]*int
+	fixableOnly bool
+}
+
+// register gate flags
+func (f *gateFlags) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&f.failOn, "fail-on", "",
+		"Fail when any finding has this severity or higher (critical, high, medium or low)")
+	f.maxFindings = make(map[string]*int, len(types.GateSeverities))
+	for _, level := range types.GateSeverities {
+		f.maxFindings[level] = flags.Int("max-"+level, -1,
+			fmt.Sprintf("Fail when more than this many %s findings are found (-1 for unlimited)", level))
+	}
+	flags.BoolVar(&f.fixableOnly, "fail-on-fixable-only", false,
+		"Only count findings with a fix available against --fail-on and --max-* budgets")
+}
+
+// build gate from flags, nil when no budget is set
+func (f *gateFlags) gate() (*types.ScanGate, error) {
+	gate := &types.ScanGate{FailOn: f.failOn, MaxFindings: map[string]int{}, FixableOnly: f.fixableOnly}
+	for level, limit := range f.maxFindings {
+		if *limit >= 0 {
+			gate.MaxFindings[level] = *limit
+		}
+	}
+
+	if gate.FailOn == "" && len(gate.MaxFindings) == 0 {
+		if gate.FixableOnly {
+			return nil, fmt.Errorf("--fail-on-fixable-only requires --fail-on or a --max-* budget")
+		}
+		return nil, nil
+	}
+	return gate, nil
+}
+
+func newSignCommand() *cobra.Command {
+	var inputFile string
+	var outputFormat string
+	var out attestation.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign an in-toto statement into a DSSE envelope or Sigstore bundle",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := attestation.ParseOutputFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+			out.Format = format
+			if out.SignCert != "" && format != attestation.OutputFormatBundle {
+				return fmt.Errorf("--cert can only be used with bundle output")
+			}
+			return attestation.SignStatementFile(inputFile, out)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&inputFile, "input", "", "Path to the in-toto statement JSON file")
+	flags.StringVar(&out.SignKey, "key", "", "PEM private key (ECDSA P-256, Ed25519 or RSA)")
+	flags.StringVar(&out.SignCert, "cert", "", "PEM certificate for the signing key, embedded in bundle output")
+	flags.StringVar(&outputFormat, "output-format", "dsse", "Output format (dsse or bundle)")
+	flags.StringVar(&out.File, "output", "", "Output file path (defaults to stdout)")
+	cobra.CheckErr(cmd.MarkFlagRequired("input"))
+	cobra.CheckErr(cmd.MarkFlagRequired("key"))
+
+	return cmd
+}
+
+func newVerifyCommand() *cobra.Command {
+	var opts attestation.VerifyOptions
+	var blob blobFlags
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify a DSSE signed attestation",
+		Long: `Verify a DSSE signed attestation against public keys, a subject and the attestation schemas.
+
+Exit codes:
+  2  signature verification failed
+  3  subject digest mismatch
+  4  schema validation failed`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.SubjectPath != "" && opts.SubjectDigest != "" {
+				return fmt.Errorf("--subject-path and --subject-digest are mutually exclusive")
+			}
+			if opts.SubjectPath == "" && opts.SubjectDigest == "" {
+				return fmt.Errorf("--subject-path or --subject-digest is required")
+			}
+
+			// any one of the digests computed for --subject-path must match
+			digest, err := blob.digestOptions()
+			if err != nil {
+				return err
+			}
+			opts.Digest = digest
+
+			statement, err := attestation.Verify(opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Verified %s attestation for %d subject(s)\n",
+				statement.PredicateType, len(statement.Subject))
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.EnvelopePath, "envelope", "", "Path to the DSSE envelope or Sigstore bundle JSON file")
+	flags.StringArrayVar(&opts.KeyPaths, "key", nil, "PEM public key or key set file (repeatable)")
+	flags.StringVar(&opts.SubjectPath, "subject-path", "", "Path to the subject file or directory")
+	flags.StringVar(&opts.SubjectDigest, "subject-digest", "",
+		"Expected subject digest (e.g. sha256:abc..., sha512:def... or gitoid:blob:sha256:...), comma separated for several digests of one subject")
+	blob.registerDigest(cmd)
+	cobra.CheckErr(cmd.MarkFlagRequired("envelope"))
+	cobra.CheckErr(cmd.MarkFlagRequired("key"))
+
+	return cmd
+}
+
+func newPushCommand() *cobra.Command {
+	var opts attestation.PushOptions
+	var mode string
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Attach an attestation to an image in an OCI registry",
+		Long: `Attach an in-toto statement, DSSE envelope or Sigstore bundle to an image as an OCI artifact.
+
+The artifact's subject is the image manifest digest, which must match a subject of the attestation.
+In auto mode the OCI 1.1 referrers API is used when the registry supports it, otherwise the
+attestation is added to the sha256-<digest>.att tag.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pushMode, err := oci.ParsePushMode(mode)
+			if err != nil {
+				return err
+			}
+			opts.Mode = pushMode
+
+			result, err := attestation.Push(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Pushed attestation for %s to %s (%s)\n",
+				result.SubjectDigest, result.Reference, result.Mode)
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.InputPath, "input", "", "Path to the statement, DSSE envelope or Sigstore bundle JSON file")
+	flags.StringVar(&opts.Image, "image", "", "Image reference (tag or digest) to attach the attestation to")
+	flags.StringVar(&mode, "mode", "auto", "How to attach the attestation (auto, referrers or tag)")
+	cobra.CheckErr(cmd.MarkFlagRequired("input"))
+	cobra.CheckErr(cmd.MarkFlagRequired("image"))
+
+	return cmd
+}
+
+func newUploadCommand() *cobra.Command {
+	var opts attestation.UploadOptions
+
+	cmd := &cobra.Command{
+		Use:   "upload",
+		Short: "Upload a Sigstore bundle to the GitHub Attestations API",
+		Long: `Upload a Sigstore bundle to the GitHub Attestations API of a repository, where
+gh attestation download and fetch --digest can find it by subject digest. The bundle
+has no transparency log entry, verify it with autogov-helper verify or cosign.
+
+Requires GH_TOKEN or GITHUB_TOKEN with attestations:write. GitHub Enterprise Server is
+used when GITHUB_API_URL is set.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := attestation.Upload(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Uploaded attestation %d to %s\n", result.ID, result.Repository)
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.InputPath, "input", "", "Path to the Sigstore bundle JSON file")
+	flags.StringVar(&opts.Repository, "repository", "", "Repository (owner/repo) to store the attestation in (default $GITHUB_REPOSITORY)")
+	cobra.CheckErr(cmd.MarkFlagRequired("input"))
+
+	return cmd
+}
+
+func newFetchCommand() *cobra.Command {
+	var opts attestation.FetchOptions
+	var outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "fetch",
+		Short: "Download attestations stored for a subject",
+		Long: `Download the attestations stored for a subject digest in the GitHub Attestations API
+(--digest), or attached to an image in its OCI registry through referrers and the
+sha256-<digest>.att tag (--image).
+
+Attestations are written to the output directory as sha256-<content digest>.json.
+--predicate-type keeps only matching attestations and accepts the metadata, depscan,
+provenance, spdx, cyclonedx, static-analysis and openvex aliases.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fetched, err := attestation.Fetch(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+			if len(fetched) == 0 {
+				return fmt.Errorf("no attestations found")
+			}
+
+			paths, err := attestation.SaveAttestations(outputDir, fetched)
+			if err != nil {
+				return err
+			}
+			for i, path := range paths {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", path, fetched[i].PredicateType, fetched[i].Source)
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.Digest, "digest", "", "Subject digest (sha256:...) to look up in the GitHub Attestations API")
+	flags.StringVar(&opts.Repository, "repository", "", "Repository (owner/repo) holding the attestations (default $GITHUB_REPOSITORY)")
+	flags.StringVar(&opts.Image, "image", "", "Image reference whose registry attestations are fetched")
+	flags.StringSliceVar(&opts.PredicateTypes, "predicate-type", nil,
+		"Only fetch attestations with these predicate types "+
+			"(URI, metadata, depscan, provenance, spdx, cyclonedx, static-analysis or openvex)")
+	flags.StringVar(&outputDir, "output-dir", ".", "Directory to write attestations to")
+	cmd.MarkFlagsMutuallyExclusive("digest", "image")
+	cmd.MarkFlagsOneRequired("digest", "image")
+
+	return cmd
+}
+
+func newProvenanceCommand() *cobra.Command {
+	var opts attestation.ProvenanceOptions
+	var output outputFlags
+	var artifactType string
+	var subject subjectFlags
+
+	cmd := &cobra.Command{
+		Use:   "provenance",
+		Short: "Generate SLSA provenance v1 attestation",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := output.options(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts.Subjects, _, err = subject.resolve(artifactType)
+			if err != nil {
+				return err
+			}
+
+			// load github context
+			ctx, err := attestation.LoadGitHubContext()
+			if err != nil {
+				return fmt.Errorf("failed to load GitHub context: %w", err)
+			}
+			if ctx.WorkflowRef == "" || ctx.SHA == "" || ctx.RunID == "" {
+				return fmt.Errorf("GITHUB_WORKFLOW_REF, GITHUB_SHA and GITHUB_RUN_ID are required for provenance")
+			}
+
+			opts.ServerURL = ctx.ServerURL
+			if opts.ServerURL == "" {
+				opts.ServerURL = "https://github.com"
+			}
+			opts.Repository = ctx.Repository
+			opts.RepositoryID = ctx.RepositoryID
+			opts.RepositoryOwnerID = ctx.RepositoryOwnerID
+			opts.WorkflowRef = ctx.WorkflowRef
+			opts.Inputs = ctx.Inputs
+			opts.Event = ctx.EventName
+			opts.Environment = ctx.Runner.Environment
+			opts.Ref = ctx.Ref
+			opts.SHA = ctx.SHA
+			opts.RunID = ctx.RunID
+			opts.RunAttempt = ctx.RunAttempt
+
+			// workflow run creation time starts the build
+			if startTime, err := time.Parse(time.RFC3339, ctx.Event.WorkflowRun.CreatedAt); err == nil {
+				opts.StartedAt = startTime.UTC()
+			}
+			opts.FinishedAt = time.Now().UTC()
+
+			return attestation.GenerateProvenance(opts, out)
+		},
+	}
+
+	flags := cmd.Flags()
+	subject.register(cmd, "being attested")
+	output.register(cmd, "Output file path (defaults to stdout)")
+	flags.StringVar(&artifactType, "type", "image", "Type of artifact (image or blob)")
+
+	return cmd
+}
+
+func newSBOMCommand() *cobra.Command {
+	var opts attestation.SBOMOptions
+	var output outputFlags
+	var artifactType string
+	var subject subjectFlags
+
+	cmd := &cobra.Command{
+		Use:   "sbom",
+		Short: "Generate SBOM attestation from an SPDX or CycloneDX document",
+		Long: `Wrap an SPDX 2.3 or CycloneDX 1.5/1.6 JSON document as the predicate of an SBOM attestation.
+
+The document format sets the predicate type (https://spdx.dev/Document or https://cyclonedx.org/bom).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := output.options(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts.Subjects, _, err = subject.resolve(artifactType)
+			if err != nil {
+				return err
+			}
+
+			return attestation.GenerateSBOM(opts, out)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.DocumentPath, "sbom-path", "", "Path to the SPDX or CycloneDX JSON document")
+	subject.register(cmd, "described by the SBOM")
+	output.register(cmd, "Output file path (defaults to stdout)")
+	flags.StringVar(&artifactType, "type", "image", "Type of artifact (image or blob)")
+	cobra.CheckErr(cmd.MarkFlagRequired("sbom-path"))
+
+	return cmd
+}
+
+func newStaticAnalysisCommand() *cobra.Command {
+	var opts attestation.StaticAnalysisOptions
+	var output outputFlags
+	var artifactType string
+	var subject subjectFlags
+
+	cmd := &cobra.Command{
+		Use:   "static-analysis",
+		Short: "Generate static analysis attestation from SARIF results",
+		Long: `Turn the results of a SARIF 2.1.0 log (CodeQL, Semgrep, Snyk, ...) into a static analysis
+attestation with predicate type ` + types.StaticAnalysisPredicateTypeURI + `.
+
+Findings keep their rule id, level, security-severity, tags, message and source locations.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := output.options(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts.Subjects, _, err = subject.resolve(artifactType)
+			if err != nil {
+				return err
+			}
+
+			return attestation.GenerateStaticAnalysis(opts, out)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.ResultsPath, "results-path", "", "Path to SARIF 2.1.0 results file")
+	subject.register(cmd, "being analyzed")
+	output.register(cmd, "Output file path (defaults to stdout)")
+	flags.StringVar(&artifactType, "type", "image", "Type of artifact (image or blob)")
+	cobra.CheckErr(cmd.MarkFlagRequired("results-path"))
+
+	return cmd
+}
+
+func newVEXCommand() *cobra.Command {
+	var opts attestation.VEXOptions
+	var output outputFlags
+	var artifactType string
+	var subject subjectFlags
+
+	cmd := &cobra.Command{
+		Use:   "vex",
+		Short: "Generate OpenVEX attestation from a triage file",
+		Long: `Turn a YAML or JSON triage file into an OpenVEX v0.2 document about the subjects, and wrap
+it as an attestation with predicate type ` + types.OpenVEXPredicateTypeURI + `.
+
+The triage file lists statements with a vulnerability, status (not_affected, affected, fixed or
+under_investigation), justification, impact_statement, action_statement and optional
+subcomponent purls.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := output.options(cmd)
+			if err != nil {
+				return err
+			}
+
+			opts.Subjects, _, err = subject.resolve(artifactType)
+			if err != nil {
+				return err
+			}
+			opts.Type = types.ArtifactTypeBlob
+			if artifactType == "image" {
+				opts.Type = types.ArtifactTypeContainerImage
+			}
+
+			return attestation.GenerateVEX(opts, out)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.TriagePath, "triage-path", "", "Path to the YAML or JSON triage file")
+	flags.StringVar(&opts.Author, "author", "", "Author of the VEX document, when the triage file names none")
+	subject.register(cmd, "the statements are about")
+	output.register(cmd, "Output file path (defaults to stdout)")
+	flags.StringVar(&artifactType, "type", "image", "Type of artifact (image or blob)")
+	cobra.CheckErr(cmd.MarkFlagRequired("triage-path"))
+
+	return cmd
+}
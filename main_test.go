@@ -2,13 +2,21 @@ package main
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"autogov-helper/internal/oci"
 	"autogov-helper/internal/util/testutil"
 
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,6 +40,14 @@ func TestMainCommand(t *testing.T) {
 	})
 }
 
+func TestVerifyCommandFlags(t *testing.T) {
+	// directory subjects are only reproducible with the digest flags used to attest them
+	flags := newVerifyCommand().Flags()
+	for _, name := range []string{"dir-digest-algorithm", "symlinks", "digest-algorithms", "include", "exclude", "ignore-files"} {
+		assert.NotNil(t, flags.Lookup(name), name)
+	}
+}
+
 func TestMetadataCommand(t *testing.T) {
 	// set up test env
 	cleanup := testutil.SetupTestEnv(t)
@@ -146,6 +162,97 @@ func TestMetadataCommand(t *testing.T) {
 	assert.Equal(t, "write", permissions["packages"])
 }
 
+func TestMetadataCommandMultipleSubjects(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	os.Setenv("RUNNER_OS", "Linux")
+	os.Setenv("RUNNER_ARCH", "X64")
+	os.Setenv("GITHUB_REPOSITORY_OWNER", "test-org")
+
+	tmpDir := t.TempDir()
+	for _, name := range []string{"app-linux-amd64", "app-darwin-arm64", "app-windows-amd64.exe"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(name), 0600))
+	}
+	outputPath := filepath.Join(tmpDir, "metadata.json")
+
+	cmd := newRootCommand()
+	cmd.SetArgs([]string{
+		"metadata",
+		"--type", "blob",
+		"--subject-path", filepath.Join(tmpDir, "app-linux-*"),
+		"--subject-path", filepath.Join(tmpDir, "app-darwin-arm64"),
+		"--subject-path", filepath.Join(tmpDir, "app-windows-amd64.exe"),
+		"--output-format", "statement",
+		"--output", outputPath,
+	})
+	require.NoError(t, cmd.Execute())
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var statement struct {
+		Subject []struct {
+			Name   string            `json:"name"`
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+	}
+	require.NoError(t, json.Unmarshal(data, &statement))
+	require.Len(t, statement.Subject, 3)
+	assert.Equal(t, filepath.Join(tmpDir, "app-linux-amd64"), statement.Subject[0].Name)
+	for _, subject := range statement.Subject {
+		assert.Len(t, subject.Digest["sha256"], 64)
+	}
+	assert.NotEqual(t, statement.Subject[0].Digest, statement.Subject[1].Digest)
+}
+
+func TestMetadataCommandOCILayout(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	layoutDir := filepath.Join(t.TempDir(), "oci-layout")
+	p, err := layout.Write(layoutDir, empty.Index)
+	require.NoError(t, err)
+	require.NoError(t, p.AppendImage(img, layout.WithAnnotations(map[string]string{
+		oci.AnnotationImageName: "ghcr.io/test-org/test-repo:v1.0.0",
+	})))
+	outputPath := filepath.Join(t.TempDir(), "metadata.json")
+
+	cmd := newRootCommand()
+	cmd.SetArgs([]string{
+		"metadata",
+		"--type", "image",
+		"--subject-path", layoutDir,
+		"--output-format", "statement",
+		"--output", outputPath,
+	})
+	require.NoError(t, cmd.Execute())
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var statement struct {
+		Subject []struct {
+			Name   string            `json:"name"`
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+		Predicate struct {
+			Artifact map[string]any `json:"artifact"`
+		} `json:"predicate"`
+	}
+	require.NoError(t, json.Unmarshal(data, &statement))
+	require.Len(t, statement.Subject, 1)
+	assert.Equal(t, "ghcr.io/test-org/test-repo", statement.Subject[0].Name)
+	assert.Equal(t, digest.Hex, statement.Subject[0].Digest["sha256"])
+	assert.Equal(t, "ghcr.io", statement.Predicate.Artifact["registry"])
+	assert.Equal(t, "ghcr.io/test-org/test-repo:v1.0.0@"+digest.String(), statement.Predicate.Artifact["fullName"])
+}
+
 func TestDepscanCommand(t *testing.T) {
 	cleanup := testutil.SetupTestEnv(t)
 	defer cleanup()
@@ -174,14 +281,30 @@ func TestDepscanCommand(t *testing.T) {
 			{
 				"vulnerability": {
 					"id": "CVE-2024-1234",
+					"dataSource": "https://nvd.nist.gov/vuln/detail/CVE-2024-1234",
+					"namespace": "nvd:cpe",
 					"severity": "Medium",
 					"cvss": [
 						{
+							"source": "nvd@nist.gov",
+							"type": "Primary",
+							"version": "3.1",
+							"vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N",
 							"metrics": {
 								"baseScore": 7.5
 							}
 						}
-					]
+					],
+					"fix": {
+						"versions": ["1.2.4"],
+						"state": "fixed"
+					}
+				},
+				"artifact": {
+					"name": "example-lib",
+					"version": "1.2.3",
+					"type": "go-module",
+					"purl": "pkg:golang/example.com/example-lib@1.2.3"
 				}
 			}
 		]
@@ -221,6 +344,11 @@ func TestDepscanCommand(t *testing.T) {
 	assert.Equal(t, "0.74.7", scanner["version"])
 	assert.Equal(t, "https://github.com/anchore/grype/releases/tag/v0.74.7", scanner["uri"])
 
+	// scan times come from the report
+	metadata := result["metadata"].(map[string]interface{})
+	assert.Equal(t, "2024-01-27T19:48:49Z", metadata["scanStartedOn"])
+	assert.Equal(t, "2024-01-27T19:48:49Z", metadata["scanFinishedOn"])
+
 	db := scanner["db"].(map[string]interface{})
 	assert.Equal(t, "https://toolbox-data.anchore.io/grype/databases/listing.json", db["uri"])
 	assert.Equal(t, "5", db["version"])
@@ -240,6 +368,90 @@ func TestDepscanCommand(t *testing.T) {
 	assert.Equal(t, "Medium", severity1["score"])
 
 	severity2 := severities[1].(map[string]interface{})
-	assert.Equal(t, "cvss_score", severity2["method"])
+	assert.Equal(t, "cvss_v3.1:nvd", severity2["method"])
 	assert.Equal(t, "7.5", severity2["score"])
+	assert.Equal(t, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", severity2["vector"])
+
+	// package and fix availability
+	pkg := result1["package"].(map[string]interface{})
+	assert.Equal(t, "pkg:golang/example.com/example-lib@1.2.3", pkg["purl"])
+	fix := result1["fix"].(map[string]interface{})
+	assert.Equal(t, "fixed", fix["state"])
+	assert.Equal(t, []interface{}{"1.2.4"}, fix["versions"])
+}
+
+func TestSignKeyOutput(t *testing.T) {
+	cleanup := testutil.SetupTestEnv(t)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+
+	// create ed25519 signing key
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPath := filepath.Join(tmpDir, "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	resultsPath := filepath.Join(tmpDir, "results.json")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(`{
+		"descriptor": {
+			"version": "0.74.7",
+			"db": {"built": "2024-01-27T19:48:49Z", "schemaVersion": "5"}
+		},
+		"matches": []
+	}`), 0600))
+
+	t.Run("sign key implies dsse output", func(t *testing.T) {
+		outputPath := filepath.Join(tmpDir, "depscan.dsse.json")
+
+		cmd := newRootCommand()
+		cmd.SetArgs([]string{
+			"depscan",
+			"--subject-name", "test-image",
+			"--subject-digest", "sha256:test",
+			"--results-path", resultsPath,
+			"--sign-key", keyPath,
+			"--output", outputPath,
+		})
+		require.NoError(t, cmd.Execute())
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+
+		var envelope map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &envelope))
+		assert.Equal(t, "application/vnd.in-toto+json", envelope["payloadType"])
+		assert.Len(t, envelope["signatures"], 1)
+	})
+
+	t.Run("sign key conflicts with predicate output", func(t *testing.T) {
+		cmd := newRootCommand()
+		cmd.SetArgs([]string{
+			"depscan",
+			"--subject-name", "test-image",
+			"--subject-digest", "sha256:test",
+			"--results-path", resultsPath,
+			"--sign-key", keyPath,
+			"--output-format", "predicate",
+		})
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--sign-key cannot be used")
+	})
+
+	t.Run("dsse output requires key", func(t *testing.T) {
+		cmd := newRootCommand()
+		cmd.SetArgs([]string{
+			"depscan",
+			"--subject-name", "test-image",
+			"--subject-digest", "sha256:test",
+			"--results-path", resultsPath,
+			"--output-format", "dsse",
+		})
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--sign-key is required")
+	})
 }